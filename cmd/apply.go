@@ -0,0 +1,196 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/cli"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/config"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/cs"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/errs"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/parse"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/plan"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/report"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/target"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/ue"
+)
+
+func ApplyConfig(fs *flag.FlagSet) cli.RunFunc {
+	var (
+		cnfFilePath     = fs.String("config", *configPath, "config file with the desired state of the project")
+		projectFilePath = fs.String("project", *projectPath, "path to the .uproject or .uplugin file, or directory with this file (found from the working directory if empty)")
+		plugin          = fs.String("plugin", "", "name of the project plugin to apply the config to, it is searched in the plugin directories")
+		templatesDir    = fs.String("templates", "", "directory with templates overriding the built-in ones")
+	)
+
+	return func(args []string) error {
+		var err error
+
+		cnf, err := loadConfig(*cnfFilePath, config.LoadOptions{})
+		if err != nil {
+			return err
+		}
+		err = loadTemplates(cnf, *templatesDir)
+		if err != nil {
+			return err
+		}
+		projectFile, err := readPlugin(*projectFilePath, *plugin)
+		if err != nil {
+			return err
+		}
+
+		pl, err := plan.Diff(projectFile, cnf)
+		if err != nil {
+			return err
+		}
+		report.SetResult(pl)
+		pl.Print(stdout)
+		if pl.Empty() {
+			return nil
+		}
+
+		err = applyPlan(projectFile, cnf, pl)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "\nApply complete! Resources: %d added, %d changed, %d removed.\n",
+			pl.Count(plan.Add), pl.Count(plan.Update), pl.Count(plan.Remove))
+		return nil
+	}
+}
+
+func CheckConfig(fs *flag.FlagSet) cli.RunFunc {
+	var (
+		cnfFilePath     = fs.String("config", *configPath, "config file with the desired state of the project")
+		projectFilePath = fs.String("project", *projectPath, "path to the .uproject or .uplugin file, or directory with this file (found from the working directory if empty)")
+		plugin          = fs.String("plugin", "", "name of the project plugin to check, it is searched in the plugin directories")
+	)
+
+	return func(args []string) error {
+		var err error
+
+		cnf, err := loadConfig(*cnfFilePath, config.LoadOptions{})
+		if err != nil {
+			return err
+		}
+		projectFile, err := readPlugin(*projectFilePath, *plugin)
+		if err != nil {
+			return err
+		}
+
+		pl, err := plan.Diff(projectFile, cnf)
+		if err != nil {
+			return err
+		}
+		report.SetResult(pl)
+		if !pl.Empty() {
+			return errs.Wrap(errs.Drift, &plan.DriftError{Plan: pl})
+		}
+		fmt.Fprintln(stdout, "The project matches the config.")
+		return nil
+	}
+}
+
+// applyPlan makes the changes of the plan: the descriptor entries are updated in place,
+// the dependencies are added to and removed from the existing Build.cs arrays of their blocks.
+// The config is only read, the created modules don't add their file requirements to it
+func applyPlan(projectFile *ue.ProjectFileDescriptor, cnf *config.AppConfig, pl *plan.Plan) error {
+	var err error
+	var descriptorChanged bool
+	for _, c := range pl.Changes {
+		switch c.Resource {
+		case plan.ResourceModule:
+			descriptorChanged = true
+			err = applyModuleChange(projectFile, cnf, c)
+		case plan.ResourceExternalModule:
+			err = parse.WriteExternalModule(projectFile, cnf.Module(c.Name), cnf.Project.Copyright.Text)
+		case plan.ResourceDependency:
+			err = applyDependencyChange(projectFile, c)
+		case plan.ResourcePlugin:
+			descriptorChanged = true
+			applyPluginChange(projectFile, cnf, c)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", c.Title(), err)
+		}
+	}
+	if !descriptorChanged {
+		return nil
+	}
+	return parse.WriteProjectDescriptor(projectFile)
+}
+
+func applyModuleChange(projectFile *ue.ProjectFileDescriptor, cnf *config.AppConfig, c *plan.Change) error {
+	mc := cnf.Module(c.Name)
+	switch c.Action {
+	case plan.Add:
+		return createModuleFiles(projectFile, mc, cnf, false)
+	case plan.Update:
+		for _, mdl := range projectFile.Modules {
+			if mdl.Name == c.Name {
+				mdl.Type = mc.Type
+				mdl.LoadingPhase = mc.LoadingPhase
+			}
+		}
+	case plan.Remove:
+		var modules []*ue.ProjectModuleDescriptor
+		for _, mdl := range projectFile.Modules {
+			if mdl.Name != c.Name {
+				modules = append(modules, mdl)
+			}
+		}
+		projectFile.Modules = modules
+		if !projectFile.IsPlugin {
+			_, err := target.RemoveModule(projectFile, c.Name)
+			if err != nil {
+				return err
+			}
+		}
+		report.Warn("module %s is removed from %s, its sources in %s are kept", c.Name, projectFile.ProjectFileName, projectFile.ModuleSources(c.Name))
+	}
+	return nil
+}
+
+// applyDependencyChange edits the dependency array in the block of the Build.cs with the condition of the dependency,
+// the missing if block is added. The dependency with the wrong condition is moved to the block of the right one
+func applyDependencyChange(projectFile *ue.ProjectFileDescriptor, c *plan.Change) error {
+	_, err := parse.EditBuildCs(projectFile, c.Module, func(src *cs.Source) error {
+		if from, to, ok := c.ConditionChange(); ok {
+			src.RemoveIf(from, c.Property, c.Name)
+			return src.AddIf(to, c.Property, c.Name)
+		}
+		if c.Action == plan.Remove {
+			src.RemoveIf(c.Condition, c.Property, c.Name)
+			return nil
+		}
+		return src.AddIf(c.Condition, c.Property, c.Name)
+	})
+	return err
+}
+
+func applyPluginChange(projectFile *ue.ProjectFileDescriptor, cnf *config.AppConfig, c *plan.Change) {
+	var want *ue.PluginDescriptor
+	for _, pl := range cnf.Project.Plugins {
+		if pl.Name == c.Name {
+			want = &ue.PluginDescriptor{Name: pl.Name, Enabled: pl.Enabled, Optional: pl.Optional}
+		}
+	}
+	switch c.Action {
+	case plan.Add:
+		projectFile.Plugins = append(projectFile.Plugins, want)
+	case plan.Update:
+		for _, pl := range projectFile.Plugins {
+			if pl.Name == c.Name {
+				pl.Enabled = want.Enabled
+				pl.Optional = want.Optional
+			}
+		}
+	case plan.Remove:
+		var plugins []*ue.PluginDescriptor
+		for _, pl := range projectFile.Plugins {
+			if pl.Name != c.Name {
+				plugins = append(plugins, pl)
+			}
+		}
+		projectFile.Plugins = plugins
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const gameConfig = `modules:
+  - name: Game
+    dependencies:
+      public: [Core, Engine]
+`
+
+// TestApplyKeepsUnmanagedPlugins applies the config without the plugins key to the project,
+// that references the plugins: the references are not the part of the plan
+func TestApplyKeepsUnmanagedPlugins(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"game.yaml": gameConfig,
+		"Game.uproject": `{
+	"FileVersion": 3,
+	"EngineAssociation": "5.3",
+	"Plugins": [
+		{
+			"Name": "Niagara",
+			"Enabled": true
+		}
+	]
+}
+`,
+	})
+	if code, out := runTool(t, dir, "module", "create", "--config", "game.yaml"); code != 0 {
+		t.Fatalf("module create exited with %d:\n%s", code, out)
+	}
+	before, err := os.ReadFile(filepath.Join(dir, "Game.uproject"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, out := runTool(t, dir, "--output", "json", "apply", "--config", "game.yaml")
+	if code != 0 {
+		t.Fatalf("apply exited with %d:\n%s", code, out)
+	}
+	var res struct {
+		Result struct {
+			Changes []json.RawMessage `json:"changes"`
+		} `json:"result"`
+	}
+	if err = json.Unmarshal([]byte(out), &res); err != nil {
+		t.Fatalf("apply output: %v\n%s", err, out)
+	}
+	if len(res.Result.Changes) != 0 {
+		t.Errorf("apply plan must be empty:\n%s", out)
+	}
+	after, err := os.ReadFile(filepath.Join(dir, "Game.uproject"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != string(before) {
+		t.Errorf("apply changed the descriptor:\n%s\nwant:\n%s", after, before)
+	}
+}
+
+// TestCheckAfterPluginCreate checks the project with the config without the plugins key
+// after the plugin was created: the reference added by plugin create is not the drift
+func TestCheckAfterPluginCreate(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"game.yaml":     gameConfig,
+		"Game.uproject": emptyProject,
+		"plugin.yaml": `project:
+  name: ExamplePlugin
+modules:
+  - name: ExamplePlugin
+`,
+	})
+	if code, out := runTool(t, dir, "module", "create", "--config", "game.yaml"); code != 0 {
+		t.Fatalf("module create exited with %d:\n%s", code, out)
+	}
+	if code, out := runTool(t, dir, "plugin", "create", "--config", "plugin.yaml"); code != 0 {
+		t.Fatalf("plugin create exited with %d:\n%s", code, out)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "Game.uproject"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"ExamplePlugin"`) {
+		t.Fatalf("plugin create must reference the plugin in the project:\n%s", b)
+	}
+
+	if code, out := runTool(t, dir, "check", "--config", "game.yaml"); code != 0 {
+		t.Errorf("check exited with %d:\n%s", code, out)
+	}
+}
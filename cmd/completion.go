@@ -0,0 +1,111 @@
+package main
+
+import (
+	"github.com/sajoniks/ue-tools/module-tool/pkg/parse"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/ue"
+	"os"
+	"path/filepath"
+)
+
+// completionProject returns the directory of the project and the plugin to complete the names from
+func completionProject() (string, *ue.ProjectFileDescriptor) {
+	loc, err := parse.FindProject(*projectPath)
+	if err != nil {
+		return "", nil
+	}
+	if loc.Project != nil {
+		return loc.Project.ProjectPath, loc.Plugin
+	}
+	return "", loc.Plugin
+}
+
+// projectPlugins returns the descriptors of the project plugins
+func projectPlugins(projectDir string) []*ue.ProjectFileDescriptor {
+	project, err := parse.ReadProjectFile(projectDir)
+	if err != nil || project.IsPlugin {
+		return nil
+	}
+	idx, _ := parse.DiscoverPlugins(project)
+	var plugins []*ue.ProjectFileDescriptor
+	for _, name := range idx.Names() {
+		plugins = append(plugins, idx[name].Descriptor)
+	}
+	return plugins
+}
+
+// moduleNames returns the names of the project modules, including the modules of its plugins
+func moduleNames() []string {
+	dir, plugin := completionProject()
+	var descriptors []*ue.ProjectFileDescriptor
+	if dir != "" {
+		if projectFile, err := parse.ReadProjectFile(dir); err == nil {
+			descriptors = append(descriptors, projectFile)
+		}
+		descriptors = append(descriptors, projectPlugins(dir)...)
+	} else if plugin != nil {
+		descriptors = append(descriptors, plugin)
+	}
+	var names []string
+	for _, desc := range descriptors {
+		for _, mdl := range desc.Modules {
+			names = append(names, mdl.Name)
+		}
+	}
+	return names
+}
+
+// projectCandidates returns the project directory and the directories of its plugins for --project
+func projectCandidates() []string {
+	dir, _ := completionProject()
+	if dir == "" {
+		return nil
+	}
+	wd, _ := os.Getwd()
+	rel := func(p string) string {
+		if r, err := filepath.Rel(wd, p); err == nil {
+			return r
+		}
+		return p
+	}
+	res := []string{rel(dir)}
+	for _, plugin := range projectPlugins(dir) {
+		res = append(res, rel(plugin.ProjectPath))
+	}
+	return res
+}
+
+// complete returns the candidates for the values of the flags, that are shared by the commands
+func complete(flag string, args []string) []string {
+	switch flag {
+	case "project":
+		return projectCandidates()
+	case "module":
+		return moduleNames()
+	case "plugin":
+		dir, _ := completionProject()
+		var names []string
+		for _, plugin := range projectPlugins(dir) {
+			names = append(names, plugin.ProjectName)
+		}
+		return names
+	case "output":
+		return []string{outputText, outputJson}
+	case "type":
+		return ue.TargetTypes()
+	case "preset":
+		names := make([]string, len(ue.ClassPresets))
+		for i, preset := range ue.ClassPresets {
+			names[i] = preset.Name
+		}
+		return names
+	}
+	return nil
+}
+
+// completeModuleArg completes the module name as the first positional argument
+func completeModuleArg(flag string, args []string) []string {
+	if flag == "" && len(args) == 0 {
+		return moduleNames()
+	}
+	return nil
+}
@@ -1,117 +1,1004 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/cli"
 	"github.com/sajoniks/ue-tools/module-tool/pkg/config"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/errs"
 	"github.com/sajoniks/ue-tools/module-tool/pkg/factory"
 	"github.com/sajoniks/ue-tools/module-tool/pkg/parse"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/plan"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/printer"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/report"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/target"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/ue"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/wizard"
+	"io"
 	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
 )
 
 const App = "module-tool"
 
-func ModuleHandler(args []string) {
-	cmd, subArgs := args[0], args[1:]
-	switch cmd {
-	case "create":
-		CreateModule(subArgs)
-	default:
-		fmt.Fprintf(os.Stderr, "unknown command: - %q", cmd)
-		os.Exit(-1)
+const (
+	outputText = "text"
+	outputJson = "json"
+)
+
+var (
+	globalFlags = flag.NewFlagSet(App, flag.ContinueOnError)
+
+	output      = globalFlags.String("output", outputText, "output format: text or json")
+	projectPath = globalFlags.String("project", "", "path to the .uproject or .uplugin file, or directory with this file (found from the working directory if empty)")
+	configPath  = globalFlags.String("config", "", "config file")
+	verbose     = globalFlags.Bool("verbose", false, "print the progress and the written files to stderr")
+	quiet       = globalFlags.Bool("quiet", false, "print only the errors")
+
+	// stdout is the output of the commands in the text mode, it's discarded in the JSON and quiet modes
+	stdout io.Writer = commandOutput{}
+)
+
+type commandOutput struct{}
+
+func (commandOutput) Write(p []byte) (int, error) {
+	if jsonOutput() || *quiet {
+		return len(p), nil
 	}
+	return os.Stdout.Write(p)
 }
 
-func CreateModule(args []string) {
-	fs := flag.NewFlagSet("create module", flag.ExitOnError)
+func jsonOutput() bool {
+	return *output == outputJson
+}
 
+func CreateModule(fs *flag.FlagSet) cli.RunFunc {
 	var (
-		cnfFilePath     = fs.String("config", "", "config file to read the plugin data from")
-		projectFilePath = fs.String("project", "", "path to the .uproject or .uplugin file, or directory with this file")
+		cnfFilePath     = fs.String("config", *configPath, "config file to read the plugin data from")
+		projectFilePath = fs.String("project", *projectPath, "path to the .uproject or .uplugin file, or directory with this file (found from the working directory if empty)")
+		templatesDir    = fs.String("templates", "", "directory with templates overriding the built-in ones")
+		external        = fs.String("external", "", "create only the external (third-party) module with the name, using its description from the config, if any")
+		plugin          = fs.String("plugin", "", "name of the project plugin to create the modules in, it is searched in the Plugins folder")
 	)
 
-	err := fs.Parse(args)
-	if err != nil {
-		panic(err)
+	return func(args []string) error {
+		var err error
+
+		cnf, err := loadConfig(*cnfFilePath, config.LoadOptions{})
+		if err != nil {
+			return err
+		}
+		err = loadTemplates(cnf, *templatesDir)
+		if err != nil {
+			return err
+		}
+		projectFile, err := readPlugin(*projectFilePath, *plugin)
+		if err != nil {
+			return err
+		}
+
+		if *external != "" {
+			mc := cnf.Module(*external)
+			if mc == nil {
+				mc = &config.ModuleConfig{Name: *external, Kind: config.KindExternal}
+			}
+			return parse.WriteExternalModule(projectFile, mc, cnf.Project.Copyright.Text)
+		}
+
+		return createModules(projectFile, cnf, false)
 	}
+}
 
-	cnf := config.MustLoadProjectConfig(*cnfFilePath)
-	projectFile, err := parse.ReadProjectFile(*projectFilePath)
-	if err != nil {
-		panic(err)
+// createModules creates the modules of the config in the project.
+// With skipExisting, the modules already in the project are skipped with a warning
+func createModules(projectFile *ue.ProjectFileDescriptor, cnf *config.AppConfig, skipExisting bool) error {
+	var skipped int
+	for i := range cnf.Modules {
+		mc := &cnf.Modules[i]
+		if skipExisting && moduleExists(projectFile, mc) {
+			report.Warn("module %s already exists in %s, skipped", mc.Name, projectFile.ProjectName)
+			skipped++
+			continue
+		}
+		err := createModuleFiles(projectFile, mc, cnf, true)
+		if err != nil {
+			return fmt.Errorf("module %s: %w", mc.Name, err)
+		}
+	}
+	if skipped == len(cnf.Modules) {
+		return nil
 	}
 
-	for i, _ := range cnf.Modules {
-		module, createErr := factory.CreateModule(projectFile, cnf.Modules[i].Name)
-		if createErr != nil {
-			break
+	return parse.WriteProjectDescriptor(projectFile)
+}
+
+// moduleExists checks the descriptor for the regular modules, and the sources for the external ones
+func moduleExists(projectFile *ue.ProjectFileDescriptor, mc *config.ModuleConfig) bool {
+	if mc.IsExternal() {
+		_, err := os.Stat(projectFile.ThirdPartySources(mc.Name))
+		return err == nil
+	}
+	for _, mdl := range projectFile.Modules {
+		if mdl.Name == mc.Name {
+			return true
 		}
-		createErr = parse.WriteProjectModule(projectFile, module, cnf)
 	}
+	return false
+}
+
+// createModuleFiles adds the module from the config to the project and writes its files.
+// With writeConfig the modules of the file requirements are added to the dependencies in the config file
+func createModuleFiles(projectFile *ue.ProjectFileDescriptor, mc *config.ModuleConfig, cnf *config.AppConfig, writeConfig bool) error {
+	if mc.IsExternal() {
+		return parse.WriteExternalModule(projectFile, mc, cnf.Project.Copyright.Text)
+	}
+	module, err := createConfigModule(projectFile, mc)
 	if err != nil {
-		panic(err)
+		return err
+	}
+	err = parse.WriteProjectModule(projectFile, module, cnf)
+	if err != nil {
+		return err
+	}
+	if reqs := mc.FileRequirements(); writeConfig && len(reqs) > 0 {
+		_, err = config.AddDependencies(cnf.File, module.Name, true, reqs...)
+		if err != nil {
+			return err
+		}
 	}
+	if !projectFile.IsPlugin {
+		return registerTargetModule(projectFile, module, cnf)
+	}
+	return nil
 }
 
-func PluginHandler(args []string) {
-	cmd, subArgs := args[0], args[1:]
-	switch cmd {
-	case "create":
-		CreatePlugin(subArgs)
-	default:
-		fmt.Fprintf(os.Stderr, "unknown command: - %q", cmd)
-		os.Exit(-1)
+// createConfigModule adds the module to the project with the type and loading phase from the config
+func createConfigModule(projectFile *ue.ProjectFileDescriptor, mc *config.ModuleConfig) (*ue.ProjectModuleDescriptor, error) {
+	module, err := factory.CreateModule(projectFile, mc.Name)
+	if err != nil {
+		return nil, err
+	}
+	module.Type = mc.Type
+	module.LoadingPhase = mc.LoadingPhase
+	return module, nil
+}
+
+func targetSettings(cnf *config.AppConfig) target.Settings {
+	return target.Settings{
+		Copyright:           cnf.Project.Copyright.Text,
+		BuildSettings:       cnf.Targets.BuildSettings,
+		IncludeOrderVersion: cnf.Targets.IncludeOrderVersion,
 	}
 }
 
-func CreatePlugin(args []string) {
-	fs := flag.NewFlagSet("create plugin", flag.ExitOnError)
+// registerTargetModule creates project targets, if there are none, and adds the module to ExtraModuleNames
+func registerTargetModule(projectFile *ue.ProjectFileDescriptor, module *ue.ProjectModuleDescriptor, cnf *config.AppConfig) error {
+	_, err := target.EnsureTargets(projectFile, cnf.Targets.Types, targetSettings(cnf))
+	if err != nil {
+		return err
+	}
+	_, err = target.AddModule(projectFile, module)
+	return err
+}
 
+// EditModuleDeps adds or removes dependencies in the module's Build.cs
+func EditModuleDeps(fs *flag.FlagSet, add bool) cli.RunFunc {
 	var (
-		cnfFilePath     = fs.String("config", "", "config file to read the plugin data from")
-		projectFilePath = fs.String("project", "", "path to the .uproject or .uplugin file, or directory with this file")
+		cnfFilePath     = fs.String("config", *configPath, "config file to update along with the Build.cs (optional)")
+		projectFilePath = fs.String("project", *projectPath, "path to the .uproject or .uplugin file, or directory with this file (found from the working directory if empty)")
+		public          = fs.Bool("public", false, "edit PublicDependencyModuleNames")
+		private         = fs.Bool("private", false, "edit PrivateDependencyModuleNames")
 	)
 
-	err := fs.Parse(args)
+	return func(args []string) error {
+		if len(args) == 0 {
+			return errs.New(errs.Usage, "module name is required")
+		}
+		moduleName, deps := args[0], args[1:]
+		if len(deps) == 0 {
+			return errs.New(errs.Usage, "at least one dependency is required")
+		}
+		if *public == *private {
+			return errs.New(errs.Usage, "either --public or --private is required")
+		}
+
+		projectFile, err := readProject(*projectFilePath)
+		if err != nil {
+			return err
+		}
+
+		property := parse.PrivateDependencies
+		if *public {
+			property = parse.PublicDependencies
+		}
+		var changed bool
+		if add {
+			changed, err = parse.AddModuleDependencies(projectFile, moduleName, property, deps...)
+		} else {
+			changed, err = parse.RemoveModuleDependencies(projectFile, moduleName, property, deps...)
+		}
+		if err != nil {
+			return err
+		}
+		if changed {
+			fmt.Fprintln(stdout, parse.BuildCsPath(projectFile, moduleName))
+		}
+
+		if *cnfFilePath != "" {
+			if add {
+				changed, err = config.AddDependencies(*cnfFilePath, moduleName, *public, deps...)
+			} else {
+				changed, err = config.RemoveDependencies(*cnfFilePath, moduleName, *public, deps...)
+			}
+			if err != nil {
+				return err
+			}
+			if changed {
+				fmt.Fprintln(stdout, *cnfFilePath)
+			}
+		}
+		return nil
+	}
+}
+
+func CreatePlugin(fs *flag.FlagSet) cli.RunFunc {
+	var (
+		cnfFilePath     = fs.String("config", *configPath, "config file to read the plugin data from")
+		projectFilePath = fs.String("project", *projectPath, "path to the .uproject or .uplugin file, or directory with this file (found from the working directory if empty)")
+		templatesDir    = fs.String("templates", "", "directory with templates overriding the built-in ones")
+	)
+
+	return func(args []string) error {
+		var err error
+
+		cnf, err := loadConfig(*cnfFilePath, config.LoadOptions{RequireProjectName: true})
+		if err != nil {
+			return err
+		}
+		err = loadTemplates(cnf, *templatesDir)
+		if err != nil {
+			return err
+		}
+		projectFile, err := readHostProject(*projectFilePath)
+		if err != nil {
+			return err
+		}
+
+		return createPlugin(projectFile, cnf)
+	}
+}
+
+// createPlugin creates the plugin of the config in the project and enables it
+func createPlugin(projectFile *ue.ProjectFileDescriptor, cnf *config.AppConfig) error {
+	plugin, err := factory.CreatePlugin(projectFile, cnf.Project.Name, cnf.PluginDescriptor(), true)
 	if err != nil {
-		panic(err)
+		return err
 	}
 
-	cnf := config.MustLoadProjectConfig(*cnfFilePath)
-	projectFile, err := parse.ReadProjectFile(*projectFilePath)
+	err = parse.WriteProjectFile(plugin, cnf)
 	if err != nil {
-		panic(err)
+		return err
 	}
 
-	plugin, err := factory.CreatePlugin(projectFile, cnf.Project.Name, true)
+	err = parse.WritePluginSkeleton(plugin, cnf)
 	if err != nil {
-		panic(err)
+		return err
 	}
 
-	err = parse.WriteProjectFile(plugin, cnf)
+	return parse.WriteProjectDescriptor(projectFile)
+}
+
+// locateProject finds the project and the plugin by the --project flag, or from the working directory
+func locateProject(path string) (*parse.Location, error) {
+	loc, err := parse.FindProject(path)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
+	var project, plugin string
+	if loc.Project != nil {
+		project = loc.Project.Path()
+	}
+	if loc.Plugin != nil {
+		plugin = loc.Plugin.Path()
+	}
+	report.SetLocation(project, plugin)
+	return loc, nil
 }
 
-func main() {
-	d, err := os.Getwd()
+// readProject returns the descriptor the command works on: the plugin, if the path points to it
+// or the working directory is inside it, otherwise the project
+func readProject(path string) (*ue.ProjectFileDescriptor, error) {
+	loc, err := locateProject(path)
+	if err != nil {
+		return nil, err
+	}
+	return loc.Target(), nil
+}
+
+// readHostProject returns the project, even if the path points to its plugin
+func readHostProject(path string) (*ue.ProjectFileDescriptor, error) {
+	loc, err := locateProject(path)
 	if err != nil {
-		panic(errors.New("insufficient privileges"))
+		return nil, err
+	}
+	if loc.Project == nil {
+		return nil, errs.New(errs.ProjectNotFound, "plugin %s is not inside a project", loc.Plugin.Path())
+	}
+	return loc.Project, nil
+}
+
+// readPlugin returns the plugin of the project by the name, or the descriptor of readProject, if the name is empty
+func readPlugin(path, name string) (*ue.ProjectFileDescriptor, error) {
+	if name == "" {
+		return readProject(path)
+	}
+	project, err := readHostProject(path)
+	if err != nil {
+		return nil, err
+	}
+	plugin, err := parse.FindPlugin(project, name)
+	if err != nil {
+		return nil, err
+	}
+	report.SetLocation(project.Path(), plugin.Path())
+	return plugin, nil
+}
+
+// checkPluginDirectories discovers the plugins of the project with the added plugin directories.
+// The plugins, that can't be read, are reported as the warnings. The added directories
+// must not bring the plugins with the names, that are already in the project
+func checkPluginDirectories(projectFile *ue.ProjectFileDescriptor, dirs []string) error {
+	idx, problems := parse.DiscoverPlugins(projectFile)
+	for _, err := range problems {
+		if !errs.Is(err, errs.Conflict) {
+			report.Warn("plugin is skipped: %v", err)
+		}
+	}
+	for _, name := range idx.Names() {
+		info := idx[name]
+		if len(info.Duplicates) == 0 {
+			continue
+		}
+		paths := append([]string{info.Path}, info.Duplicates...)
+		for _, path := range paths {
+			for _, dir := range dirs {
+				abs, err := filepath.Abs(dir)
+				if err == nil && strings.HasPrefix(path, abs+string(filepath.Separator)) {
+					return errs.New(errs.Conflict, "plugin %s of %s is already in the project: %s", info.Name, abs, strings.Join(paths, ", "))
+				}
+			}
+		}
+		report.Warn("plugin %s is found several times: %s", info.Name, strings.Join(paths, ", "))
+	}
+	return nil
+}
+
+func loadConfig(file string, opts config.LoadOptions) (*config.AppConfig, error) {
+	if file == "" {
+		return nil, errs.New(errs.Usage, "--config is required")
+	}
+	return config.LoadProjectConfig(file, opts)
+}
+
+// loadTemplates loads user templates from the directory given by the flag, or from the config
+func loadTemplates(cnf *config.AppConfig, dir string) error {
+	if dir == "" {
+		dir = cnf.TemplatesDir()
+	}
+	if dir == "" {
+		// the templates of the previous config, e.g. in the workspace
+		return printer.ResetTemplates()
+	}
+	return printer.LoadTemplateDir(dir)
+}
+
+func DumpTemplates(fs *flag.FlagSet) cli.RunFunc {
+	var (
+		dir       = fs.String("dir", "templates", "directory to write the built-in templates to")
+		overwrite = fs.Bool("overwrite", false, "overwrite existing template files")
+	)
+
+	return func(args []string) error {
+		var err error
+
+		files, err := printer.DumpTemplates(*dir, *overwrite)
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			fmt.Fprintln(stdout, f)
+		}
+		return nil
 	}
+}
 
-	fmt.Printf("Running %s in %s\n", App, d)
-	flag.Parse()
+func PrintConfigSchema(fs *flag.FlagSet) cli.RunFunc {
+	var (
+		out       = fs.String("out", "", "file to write the schema to, stdout if empty")
+		workspace = fs.Bool("workspace", false, "print the schema of the workspace file instead of the config")
+	)
+
+	return func(args []string) error {
+		var err error
+
+		schema := config.Schema()
+		if *workspace {
+			schema = config.WorkspaceSchema()
+		}
+		b, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			return err
+		}
+		b = append(b, '\n')
+		switch {
+		case *out != "":
+			report.Touch(*out)
+			err = os.WriteFile(*out, b, 0644)
+		case jsonOutput():
+			report.SetResult(json.RawMessage(b))
+		default:
+			_, err = stdout.Write(b)
+		}
+		return err
+	}
+}
+
+func CreateClass(fs *flag.FlagSet) cli.RunFunc {
+	var (
+		cnfFilePath     = fs.String("config", *configPath, "config file to read the copyright and templates from (optional)")
+		projectFilePath = fs.String("project", *projectPath, "path to the .uproject or .uplugin file, or directory with this file (found from the working directory if empty)")
+		templatesDir    = fs.String("templates", "", "directory with templates overriding the built-in ones")
+		moduleName      = fs.String("module", "", "module to create the class in")
+		className       = fs.String("name", "", "name of the class without the prefix")
+		parent          = fs.String("parent", "", "parent class, e.g. AActor")
+		preset          = fs.String("preset", "", "class preset: Actor, ActorComponent, SceneComponent, Object, GameInstanceSubsystem, WorldSubsystem, LocalPlayerSubsystem, DeveloperSettings, BlueprintFunctionLibrary, UserWidget")
+		include         = fs.String("include", "", "header of the parent class, if it is not the parent of the preset and its header can't be found in the project modules")
+		dir             = fs.String("dir", "", "subfolder of the module's Public and Private folders")
+	)
+
+	return func(args []string) error {
+		var err error
+
+		var copyright string
+		if *cnfFilePath != "" {
+			cnf, err := loadConfig(*cnfFilePath, config.LoadOptions{})
+			if err != nil {
+				return err
+			}
+			err = loadTemplates(cnf, *templatesDir)
+			if err != nil {
+				return err
+			}
+			copyright = cnf.Project.Copyright.Text
+		} else if *templatesDir != "" {
+			err = printer.LoadTemplateDir(*templatesDir)
+			if err != nil {
+				return err
+			}
+		}
 
-	cmd, subArgs := flag.Args()[0], flag.Args()[1:]
-	switch cmd {
-	case "plugin":
-		PluginHandler(subArgs)
-	case "module":
-		ModuleHandler(subArgs)
+		projectFile, err := readProject(*projectFilePath)
+		if err != nil {
+			return err
+		}
+
+		class, err := factory.CreateClass(projectFile, *moduleName, *className, *parent, *preset, *include, *dir)
+		if err != nil {
+			return err
+		}
+
+		files, err := parse.WriteClass(projectFile, class, copyright)
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			fmt.Fprintln(stdout, f)
+		}
+
+		if len(class.Modules) > 0 {
+			changed, err := parse.AddModuleDependencies(projectFile, class.Module, parse.PublicDependencies, class.Modules...)
+			if err != nil {
+				return err
+			}
+			if changed {
+				fmt.Fprintln(stdout, parse.BuildCsPath(projectFile, class.Module))
+			}
+			if *cnfFilePath != "" {
+				_, err = config.AddDependencies(*cnfFilePath, class.Module, true, class.Modules...)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+}
+
+func InitConfig(fs *flag.FlagSet) cli.RunFunc {
+	var (
+		out       = fs.String("out", "module-tool.yaml", "config file to write")
+		yes       = fs.Bool("yes", false, "don't ask, use the default answers")
+		overwrite = fs.Bool("overwrite", false, "overwrite existing config file")
+		name      = fs.String("name", "", "default project name (current directory name if empty)")
+		isPlugin  = fs.Bool("plugin", false, "default answer to \"Is it a plugin?\"")
+		engine    = fs.String("engine", "5.3", "default engine association")
+	)
+
+	return func(args []string) error {
+		var err error
+		if _, err = os.Stat(*out); err == nil && !*overwrite {
+			return errs.New(errs.Conflict, "config file %s already exists", *out)
+		}
+
+		defaults := wizard.Defaults{
+			Name:              *name,
+			IsPlugin:          *isPlugin,
+			EngineAssociation: *engine,
+		}
+		if defaults.Name == "" {
+			if d, err := os.Getwd(); err == nil {
+				defaults.Name = filepath.Base(d)
+			}
+		}
+
+		var buf bytes.Buffer
+		// the questions must not mix with the JSON report
+		prompts := io.Writer(os.Stdout)
+		if jsonOutput() {
+			prompts = os.Stderr
+		}
+		err = wizard.New(os.Stdin, prompts, *yes).Run(defaults, &buf)
+		if err != nil {
+			return err
+		}
+		report.Touch(*out)
+		err = os.WriteFile(*out, buf.Bytes(), 0644)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, *out)
+		return nil
+	}
+}
+
+func CreateProject(fs *flag.FlagSet) cli.RunFunc {
+	var (
+		cnfFilePath  = fs.String("config", *configPath, "config file to read the project data from")
+		dir          = fs.String("dir", ".", "directory to create the project in")
+		templatesDir = fs.String("templates", "", "directory with templates overriding the built-in ones")
+	)
+
+	return func(args []string) error {
+		var err error
+
+		cnf, err := loadConfig(*cnfFilePath, config.LoadOptions{})
+		if err != nil {
+			return err
+		}
+		err = loadTemplates(cnf, *templatesDir)
+		if err != nil {
+			return err
+		}
+		projectFile, err := factory.CreateProject(*dir, cnf.Project.Name, cnf.Project.EngineAssociation)
+		if err != nil {
+			return err
+		}
+		projectFile.Category = cnf.Project.Category
+		projectFile.Description = cnf.Project.Description
+		projectFile.Plugins = cnf.PluginDescriptor().Plugins
+
+		for i := range cnf.Modules {
+			_, err = createConfigModule(projectFile, &cnf.Modules[i])
+			if err != nil {
+				return err
+			}
+		}
+
+		err = parse.WriteProjectFile(projectFile, cnf)
+		if err != nil {
+			return err
+		}
+
+		_, err = target.EnsureTargets(projectFile, cnf.Targets.Types, targetSettings(cnf))
+		if err != nil {
+			return err
+		}
+
+		return parse.WriteProjectSkeleton(projectFile, cnf)
+	}
+}
+
+func CreateTarget(fs *flag.FlagSet) cli.RunFunc {
+	var (
+		cnfFilePath     = fs.String("config", *configPath, "config file to read the target settings from")
+		projectFilePath = fs.String("project", *projectPath, "path to the .uproject file, or directory with this file (found from the working directory if empty)")
+		targetType      = fs.String("type", "", "target type: Game, Editor, Client or Server")
+		templatesDir    = fs.String("templates", "", "directory with templates overriding the built-in ones")
+	)
+
+	return func(args []string) error {
+		var err error
+
+		cnf, err := loadConfig(*cnfFilePath, config.LoadOptions{})
+		if err != nil {
+			return err
+		}
+		err = loadTemplates(cnf, *templatesDir)
+		if err != nil {
+			return err
+		}
+		projectFile, err := readHostProject(*projectFilePath)
+		if err != nil {
+			return err
+		}
+
+		types := cnf.Targets.Types
+		if *targetType != "" {
+			t, err := ue.ParseTargetType(*targetType)
+			if err != nil {
+				return err
+			}
+			types = []ue.TargetType{t}
+		}
+		for _, t := range types {
+			var modules []string
+			for _, mdl := range projectFile.Modules {
+				if mdl.Type == ue.ModuleRuntime || t == ue.TargetEditor {
+					modules = append(modules, mdl.Name)
+				}
+			}
+			_, err = target.Create(projectFile, t, targetSettings(cnf), modules)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func EditTargetModules(fs *flag.FlagSet, add bool) cli.RunFunc {
+	var (
+		projectFilePath = fs.String("project", *projectPath, "path to the .uproject file, or directory with this file (found from the working directory if empty)")
+		moduleName      = fs.String("module", "", "name of the project module")
+	)
+
+	return func(args []string) error {
+		var err error
+
+		projectFile, err := readHostProject(*projectFilePath)
+		if err != nil {
+			return err
+		}
+
+		if add {
+			var module *ue.ProjectModuleDescriptor
+			for _, mdl := range projectFile.Modules {
+				if mdl.Name == *moduleName {
+					module = mdl
+				}
+			}
+			if module == nil {
+				return errs.New(errs.Validation, "module %q is not in the project", *moduleName)
+			}
+			_, err = target.AddModule(projectFile, module)
+		} else {
+			_, err = target.RemoveModule(projectFile, *moduleName)
+		}
+		return err
+	}
+}
+
+func EditPluginDirectories(fs *flag.FlagSet, add bool) cli.RunFunc {
+	var (
+		projectFilePath = fs.String("project", *projectPath, "path to the .uproject file, or directory with this file (found from the working directory if empty)")
+	)
+
+	return func(args []string) error {
+		if len(args) == 0 {
+			return errs.New(errs.Usage, "at least one directory is required")
+		}
+
+		projectFile, err := readHostProject(*projectFilePath)
+		if err != nil {
+			return err
+		}
+
+		var changed bool
+		for _, dir := range args {
+			// the arguments are relative to the working directory, not to the project
+			abs, err := filepath.Abs(dir)
+			if err != nil {
+				return err
+			}
+			if add {
+				if stat, err := os.Stat(abs); err != nil || !stat.IsDir() {
+					report.Warn("plugin directory %s does not exist", abs)
+				}
+				changed = projectFile.AddPluginDirectory(abs) || changed
+			} else {
+				if !projectFile.RemovePluginDirectory(abs) {
+					report.Warn("plugin directory %s is not in the project", abs)
+					continue
+				}
+				changed = true
+			}
+		}
+		if !changed {
+			return nil
+		}
+		if add {
+			err = checkPluginDirectories(projectFile, args)
+			if err != nil {
+				return err
+			}
+		}
+		err = parse.WriteProjectDescriptor(projectFile)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, projectFile.Path())
+		return nil
+	}
+}
+
+// version is set by the release build: go build -ldflags "-X main.version=v1.0.0"
+var version = ""
+
+func Version(fs *flag.FlagSet) cli.RunFunc {
+	return func(args []string) error {
+		v := version
+		var revision, buildTime, goVersion string
+		var modified bool
+		if info, ok := debug.ReadBuildInfo(); ok {
+			if v == "" {
+				v = info.Main.Version
+			}
+			goVersion = info.GoVersion
+			for _, setting := range info.Settings {
+				switch setting.Key {
+				case "vcs.revision":
+					revision = setting.Value
+				case "vcs.time":
+					buildTime = setting.Value
+				case "vcs.modified":
+					modified = setting.Value == "true"
+				}
+			}
+		}
+		if v == "" {
+			v = "(devel)"
+		}
+
+		report.SetResult(map[string]any{
+			"version":  v,
+			"revision": revision,
+			"time":     buildTime,
+			"modified": modified,
+			"go":       goVersion,
+		})
+		fmt.Fprintf(stdout, "%s %s\n", App, v)
+		if revision != "" {
+			if modified {
+				revision += " (modified)"
+			}
+			fmt.Fprintf(stdout, "commit: %s\n", revision)
+		}
+		if buildTime != "" {
+			fmt.Fprintf(stdout, "built:  %s\n", buildTime)
+		}
+		if goVersion != "" {
+			fmt.Fprintf(stdout, "go:     %s\n", goVersion)
+		}
+		return nil
+	}
+}
+
+// commands is the command tree of the tool
+func commands() *cli.Command {
+	root := &cli.Command{
+		Name:     App,
+		Short:    "Creates and edits Unreal Engine projects, plugins, modules and classes",
+		Flags:    globalFlags,
+		Before:   before,
+		Complete: complete,
+	}
+	root.Add(
+		&cli.Command{Name: "init", Short: "Create the config file with the interactive wizard", Setup: InitConfig},
+		(&cli.Command{Name: "project", Short: "Create projects and edit their settings"}).Add(
+			&cli.Command{Name: "create", Short: "Create the project with the modules from the config", Setup: CreateProject},
+			(&cli.Command{Name: "plugin-dir", Short: "Edit AdditionalPluginDirectories of the project"}).Add(
+				&cli.Command{
+					Name:  "add",
+					Args:  "<dir>...",
+					Short: "Add the directories to search the plugins in",
+					Setup: func(fs *flag.FlagSet) cli.RunFunc { return EditPluginDirectories(fs, true) },
+				},
+				&cli.Command{
+					Name:  "remove",
+					Args:  "<dir>...",
+					Short: "Remove the directories from the plugin search",
+					Setup: func(fs *flag.FlagSet) cli.RunFunc { return EditPluginDirectories(fs, false) },
+				},
+			),
+		),
+		(&cli.Command{Name: "plugin", Short: "Create plugins"}).Add(
+			&cli.Command{Name: "create", Short: "Create the plugin of the project with the modules from the config", Setup: CreatePlugin},
+		),
+		(&cli.Command{Name: "module", Short: "Create modules and edit their dependencies"}).Add(
+			&cli.Command{Name: "create", Short: "Create the modules from the config", Setup: CreateModule},
+			(&cli.Command{Name: "deps", Short: "Edit the dependencies in the module's Build.cs"}).Add(
+				&cli.Command{
+					Name:     "add",
+					Args:     "<Module> <Dep>...",
+					Short:    "Add the dependencies to the module",
+					Setup:    func(fs *flag.FlagSet) cli.RunFunc { return EditModuleDeps(fs, true) },
+					Complete: completeModuleArg,
+				},
+				&cli.Command{
+					Name:     "remove",
+					Args:     "<Module> <Dep>...",
+					Short:    "Remove the dependencies from the module",
+					Setup:    func(fs *flag.FlagSet) cli.RunFunc { return EditModuleDeps(fs, false) },
+					Complete: completeModuleArg,
+				},
+			),
+		),
+		&cli.Command{
+			Name:  "check",
+			Short: "Fail if the project diverges from the config",
+			Long: "Compare the project with the config, as apply does, without changing anything.\n" +
+				"Every difference is listed, and the command exits with code 8, if there are any",
+			Setup: CheckConfig,
+		},
+		&cli.Command{
+			Name:  "apply",
+			Short: "Make the project match the config, showing the plan of the changes",
+			Long: "Compare the modules, their types, loading phases and dependencies, and the plugin references\n" +
+				"with the config, print the plan of the changes (+ add, ~ change, - remove) and apply it.\n" +
+				"The descriptor entries and the Build.cs dependency arrays are edited in place,\n" +
+				"the sources of the removed modules are kept",
+			Setup: ApplyConfig,
+		},
+		(&cli.Command{Name: "workspace", Short: "Run the commands across the projects of the workspace"}).Add(
+			&cli.Command{
+				Name:  "apply",
+				Short: "Add the shared plugin directories, create the missing plugins and modules in every project",
+				Long: "Add the shared plugin directories to every project of the workspace, create the plugins and the modules\n" +
+					"of the project configs, that are not in the project yet. The failed project doesn't stop the others",
+				Setup: WorkspaceApply,
+			},
+		),
+		(&cli.Command{Name: "class", Short: "Create classes"}).Add(
+			&cli.Command{Name: "create", Short: "Create the class in the module", Setup: CreateClass},
+		),
+		(&cli.Command{Name: "target", Short: "Create targets and edit their modules"}).Add(
+			&cli.Command{Name: "create", Short: "Create the target files of the project", Setup: CreateTarget},
+			&cli.Command{
+				Name:  "add",
+				Short: "Add the module to ExtraModuleNames of the targets",
+				Setup: func(fs *flag.FlagSet) cli.RunFunc { return EditTargetModules(fs, true) },
+			},
+			&cli.Command{
+				Name:  "remove",
+				Short: "Remove the module from ExtraModuleNames of the targets",
+				Setup: func(fs *flag.FlagSet) cli.RunFunc { return EditTargetModules(fs, false) },
+			},
+		),
+		(&cli.Command{Name: "templates", Short: "Manage the templates of the generated files"}).Add(
+			&cli.Command{Name: "dump", Short: "Write the built-in templates to the directory", Setup: DumpTemplates},
+		),
+		(&cli.Command{Name: "config", Short: "Config file tools"}).Add(
+			&cli.Command{Name: "schema", Short: "Print JSON Schema of the config file", Setup: PrintConfigSchema},
+		),
+		&cli.Command{Name: "version", Short: "Print the version and the build info", Setup: Version},
+	)
+	root.AddCompletion()
+	root.AddHelp()
+	return root
+}
+
+// before checks the global flags and prints the banner, it's called before running any command
+func before(cmd *cli.Command) error {
+	switch *output {
+	case outputText, outputJson:
 	default:
-		fmt.Fprintf(os.Stderr, "unknown command: - %q", cmd)
-		os.Exit(-1)
+		return errs.New(errs.Usage, "unknown output format %q, want text or json", *output)
+	}
+	if *verbose && *quiet {
+		return errs.New(errs.Usage, "--verbose and --quiet are mutually exclusive")
+	}
+	if *verbose && !jsonOutput() {
+		d, err := os.Getwd()
+		if err != nil {
+			return errs.New(errs.IO, "insufficient privileges")
+		}
+		fmt.Fprintf(os.Stderr, "Running %s in %s\n", App, d)
+	}
+	return nil
+}
+
+// reportError converts the error for the JSON report
+func reportError(err error) *report.Error {
+	kind := errs.KindOf(err)
+	e := &report.Error{
+		Kind:    kind.String(),
+		Code:    kind.ExitCode(),
+		Message: err.Error(),
+	}
+	var validationErr *config.ValidationError
+	if errors.As(err, &validationErr) {
+		e.Message = fmt.Sprintf("invalid config: %d problem(s)", len(validationErr.Problems))
+		for _, p := range validationErr.Problems {
+			e.Problems = append(e.Problems, report.Problem{File: p.File, Line: p.Line, Column: p.Column, Message: p.Message})
+		}
+	}
+	var driftErr *plan.DriftError
+	if errors.As(err, &driftErr) {
+		e.Message = fmt.Sprintf("project diverges from the config: %d difference(s)", len(driftErr.Plan.Changes))
+		for _, c := range driftErr.Plan.Changes {
+			e.Problems = append(e.Problems, report.Problem{File: c.File, Message: c.Divergence()})
+		}
+	}
+	return e
+}
+
+// main exits with the code of the error kind, see package errs for the list of the codes
+func main() {
+	root := commands()
+	err := root.Execute(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	cmd := root.Current()
+	report.SetCommand(strings.TrimPrefix(strings.TrimPrefix(cmd.Path(), App), " "))
+
+	if jsonOutput() {
+		if err != nil {
+			report.Fail(reportError(err))
+		}
+		_ = report.Print(os.Stdout)
+	} else {
+		printReport(err, cmd)
+	}
+	if err != nil {
+		os.Exit(errs.KindOf(err).ExitCode())
+	}
+}
+
+// printReport prints the error, the warnings and, in the verbose mode, the written files of the command
+func printReport(err error, cmd *cli.Command) {
+	r := report.Current()
+	if *verbose {
+		if r.Project != "" {
+			fmt.Fprintf(os.Stderr, "project  %s\n", r.Project)
+		}
+		if r.Plugin != "" {
+			fmt.Fprintf(os.Stderr, "plugin   %s\n", r.Plugin)
+		}
+		for _, f := range r.Created {
+			fmt.Fprintf(os.Stderr, "created  %s\n", f)
+		}
+		for _, f := range r.Modified {
+			fmt.Fprintf(os.Stderr, "modified %s\n", f)
+		}
+	}
+	if !*quiet {
+		for _, w := range r.Warnings {
+			fmt.Fprintf(os.Stderr, "%s: warning: %s\n", App, w)
+		}
+	}
+	if err == nil {
+		return
+	}
+	for _, line := range strings.Split(err.Error(), "\n") {
+		fmt.Fprintf(os.Stderr, "%s: error: %s\n", App, line)
+	}
+	if errs.Is(err, errs.Usage) {
+		fmt.Fprintf(os.Stderr, "Run '%s --help' for usage.\n", cmd.Path())
 	}
 }
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/cli"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/config"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/errs"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/parse"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/plan"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/report"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/ue"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// projectResult is the result of the workspace command for one project
+type projectResult struct {
+	Path     string        `json:"path"`
+	Project  string        `json:"project,omitempty"`
+	OK       bool          `json:"ok"`
+	Created  []string      `json:"created"`
+	Modified []string      `json:"modified"`
+	Warnings []string      `json:"warnings"`
+	Error    *report.Error `json:"error,omitempty"`
+}
+
+func WorkspaceApply(fs *flag.FlagSet) cli.RunFunc {
+	var (
+		wsFilePath = fs.String("config", *configPath, "workspace file with the projects and the shared plugin directories")
+	)
+
+	return func(args []string) error {
+		if *wsFilePath == "" {
+			return errs.New(errs.Usage, "--config is required")
+		}
+		ws, err := config.LoadWorkspace(*wsFilePath)
+		if err != nil {
+			return err
+		}
+
+		var results []*projectResult
+		var failed []error
+		for i := range ws.Projects {
+			res, err := applyWorkspaceProject(ws, &ws.Projects[i])
+			results = append(results, res)
+			if err != nil {
+				failed = append(failed, err)
+				if !jsonOutput() {
+					fmt.Fprintf(os.Stderr, "failed  %s\n", res.Path)
+					for _, line := range strings.Split(err.Error(), "\n") {
+						fmt.Fprintf(os.Stderr, "        %s\n", line)
+					}
+				}
+			} else {
+				fmt.Fprintf(stdout, "ok      %s\n", res.Path)
+			}
+		}
+		report.SetResult(results)
+
+		if len(failed) > 0 {
+			return errs.New(errs.KindOf(failed[0]), "%d of %d projects failed", len(failed), len(results))
+		}
+		return nil
+	}
+}
+
+// applyWorkspaceProject adds the shared plugin directories to the project, creates the missing plugins
+// and modules of its configs, and checks, that the project and the plugins match the configs.
+// The files and the warnings of the project are collected into its result
+func applyWorkspaceProject(ws *config.Workspace, wp *config.WorkspaceProject) (*projectResult, error) {
+	r := report.Current()
+	created, modified, warnings := len(r.Created), len(r.Modified), len(r.Warnings)
+	res := &projectResult{Path: wp.Path}
+
+	projectFile, err := applyWorkspaceChanges(ws, wp)
+	if projectFile != nil {
+		res.Project = projectFile.Path()
+	}
+	res.OK = err == nil
+	if err != nil {
+		res.Error = reportError(err)
+	}
+	res.Created = append([]string{}, r.Created[created:]...)
+	res.Modified = append([]string{}, r.Modified[modified:]...)
+	res.Warnings = append([]string{}, r.Warnings[warnings:]...)
+	return res, err
+}
+
+func applyWorkspaceChanges(ws *config.Workspace, wp *config.WorkspaceProject) (*ue.ProjectFileDescriptor, error) {
+	projectFile, err := parse.ReadProjectFile(ws.ResolvePath(wp.Path))
+	if err != nil {
+		return nil, err
+	}
+	if projectFile.IsPlugin {
+		return projectFile, errs.New(errs.Validation, "%s is a plugin, not a project", projectFile.Path())
+	}
+
+	var changed bool
+	var dirs []string
+	for _, dir := range ws.PluginDirs {
+		abs, err := filepath.Abs(ws.ResolvePath(dir))
+		if err != nil {
+			return projectFile, err
+		}
+		if stat, err := os.Stat(abs); err != nil || !stat.IsDir() {
+			report.Warn("plugin directory %s does not exist", abs)
+		}
+		dirs = append(dirs, abs)
+		changed = projectFile.AddPluginDirectory(abs) || changed
+	}
+	err = checkPluginDirectories(projectFile, dirs)
+	if err != nil {
+		return projectFile, err
+	}
+	if changed {
+		err = parse.WriteProjectDescriptor(projectFile)
+		if err != nil {
+			return projectFile, err
+		}
+	}
+
+	var plugins []*config.AppConfig
+	for _, file := range wp.Plugins {
+		cnf, err := config.LoadProjectConfig(ws.ResolvePath(file), config.LoadOptions{RequireProjectName: true})
+		if err != nil {
+			return projectFile, err
+		}
+		plugins = append(plugins, cnf)
+		_, err = parse.FindPlugin(projectFile, cnf.Project.Name)
+		if err == nil {
+			report.Warn("plugin %s already exists in %s, skipped", cnf.Project.Name, projectFile.ProjectName)
+			continue
+		}
+		if !errs.Is(err, errs.ProjectNotFound) {
+			return projectFile, err
+		}
+		err = loadTemplates(cnf, "")
+		if err != nil {
+			return projectFile, err
+		}
+		err = createPlugin(projectFile, cnf)
+		if err != nil {
+			return projectFile, fmt.Errorf("plugin %s: %w", cnf.Project.Name, err)
+		}
+	}
+
+	var project *config.AppConfig
+	if wp.Config != "" {
+		project, err = config.LoadProjectConfig(ws.ResolvePath(wp.Config), config.LoadOptions{})
+		if err != nil {
+			return projectFile, err
+		}
+		err = loadTemplates(project, "")
+		if err != nil {
+			return projectFile, err
+		}
+		err = createModules(projectFile, project, true)
+		if err != nil {
+			return projectFile, err
+		}
+	}
+	return projectFile, checkWorkspaceProject(projectFile, project, plugins)
+}
+
+// managedPlugin checks, if the plugin is created from one of the plugin configs of the workspace project
+func managedPlugin(plugins []*config.AppConfig, name string) bool {
+	for _, cnf := range plugins {
+		if strings.EqualFold(cnf.Project.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkWorkspaceProject compares the project and its plugins with their configs,
+// the existing plugins and modules are skipped by the creation and may differ from the configs
+func checkWorkspaceProject(projectFile *ue.ProjectFileDescriptor, project *config.AppConfig, plugins []*config.AppConfig) error {
+	drift := &plan.Plan{}
+	if project != nil {
+		// the descriptor is read again with the created modules
+		desc, err := parse.ReadProjectFile(projectFile.Path())
+		if err != nil {
+			return err
+		}
+		pl, err := plan.Diff(desc, project)
+		if err != nil {
+			return err
+		}
+		for _, c := range pl.Changes {
+			// the references of the plugins, the workspace creates, are not in the project config
+			if c.Resource == plan.ResourcePlugin && c.Action == plan.Remove && managedPlugin(plugins, c.Name) {
+				continue
+			}
+			drift.Changes = append(drift.Changes, c)
+		}
+	}
+	for _, cnf := range plugins {
+		desc, err := parse.FindPlugin(projectFile, cnf.Project.Name)
+		if err != nil {
+			return err
+		}
+		pl, err := plan.Diff(desc, cnf)
+		if err != nil {
+			return fmt.Errorf("plugin %s: %w", cnf.Project.Name, err)
+		}
+		drift.Changes = append(drift.Changes, pl.Changes...)
+	}
+	if !drift.Empty() {
+		return errs.Wrap(errs.Drift, &plan.DriftError{Plan: drift})
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+)
+
+// TestWorkspaceApplyReadme runs the workspace of the README: the plugin created from the plugin config
+// is referenced by the project, but is not in the plugins of the project config. The second run checks the existing files
+func TestWorkspaceApplyReadme(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"workspace.yaml": `plugin_dirs:            # added to AdditionalPluginDirectories of every project
+  - Shared/Plugins
+projects:
+  - path: Game          # the .uproject, or directory with it
+    config: game.yaml   # modules of the project
+    plugins:            # configs of the project plugins
+      - tools.yaml
+  - path: Tools/Sandbox
+`,
+		"game.yaml": `project:
+  plugins:
+    - name: Niagara
+      enabled: true
+modules:
+  - name: Game
+    dependencies:
+      public: [Core, Engine]
+`,
+		"tools.yaml": `project:
+  name: Tools
+modules:
+  - name: Tools
+`,
+		"Game/Game.uproject": `{
+	"FileVersion": 3,
+	"EngineAssociation": "5.3",
+	"Plugins": [
+		{
+			"Name": "Niagara",
+			"Enabled": true
+		}
+	]
+}
+`,
+		"Tools/Sandbox/Sandbox.uproject": emptyProject,
+		"Shared/Plugins/.keep":           "",
+	})
+
+	for _, run := range []string{"first", "second"} {
+		code, out := runTool(t, dir, "workspace", "apply", "--config", "workspace.yaml")
+		if code != 0 {
+			t.Fatalf("%s workspace apply exited with %d:\n%s", run, code, out)
+		}
+	}
+}
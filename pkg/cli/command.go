@@ -0,0 +1,307 @@
+// Package cli is the command tree of the tool: dispatching of the subcommands, flags parsing,
+// help and shell completion
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/errs"
+	"io"
+	"os"
+	"sort"
+)
+
+// RunFunc runs the command with the positional arguments, left after parsing the flags
+type RunFunc func(args []string) error
+
+// Command is the node of the command tree. Leaf commands have Setup, groups have Commands
+type Command struct {
+	Name string
+	// Args is the synopsis of the positional arguments, e.g. "<Module> <Dep>..."
+	Args  string
+	Short string
+	// Long is the description shown in the help, Short is used if empty
+	Long string
+
+	// Setup defines the flags of the command and returns the function running it
+	Setup func(fs *flag.FlagSet) RunFunc
+	// Complete returns candidates for the value of the flag, or for the positional argument, if flag is empty.
+	// The root's Complete is used for the global flags
+	Complete func(flag string, args []string) []string
+
+	Commands []*Command
+	// Hidden commands are not listed in the help and completion
+	Hidden bool
+	// RawArgs commands get all arguments as is, without parsing the flags
+	RawArgs bool
+
+	// Flags are the global flags, they are accepted by the root before the command
+	// and by every leaf command after it. Only the root's flags are used
+	Flags *flag.FlagSet
+	// Before is called before running the leaf command, when all flags are parsed. Only the root's hook is used
+	Before func(cmd *Command) error
+
+	parent *Command
+	// current is the last dispatched command, set on the root
+	current *Command
+}
+
+func (c *Command) root() *Command {
+	for c.parent != nil {
+		c = c.parent
+	}
+	return c
+}
+
+// Path is the full name of the command, e.g. "module-tool module create"
+func (c *Command) Path() string {
+	if c.parent == nil {
+		return c.Name
+	}
+	return c.parent.Path() + " " + c.Name
+}
+
+// Add adds the subcommands
+func (c *Command) Add(cmds ...*Command) *Command {
+	for _, sub := range cmds {
+		sub.parent = c
+		c.Commands = append(c.Commands, sub)
+	}
+	return c
+}
+
+// Find returns the subcommand with the name
+func (c *Command) Find(name string) *Command {
+	for _, sub := range c.Commands {
+		if sub.Name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+// Current returns the last command, the arguments were dispatched to
+func (c *Command) Current() *Command {
+	return c.root().current
+}
+
+func (c *Command) isLeaf() bool {
+	return c.Setup != nil
+}
+
+// flagSet creates the flag set of the leaf command along with the global flags
+func (c *Command) flagSet() (*flag.FlagSet, RunFunc) {
+	fs := flag.NewFlagSet(c.Path(), flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	run := c.Setup(fs)
+	if global := c.root().Flags; global != nil {
+		global.VisitAll(func(f *flag.Flag) {
+			if fs.Lookup(f.Name) == nil {
+				fs.Var(f.Value, f.Name, f.Usage)
+			}
+		})
+	}
+	return fs, run
+}
+
+// Execute parses the global flags and runs the command given by the arguments.
+// flag.ErrHelp is returned, if the help was printed on request
+func (c *Command) Execute(args []string) error {
+	c.current = c
+	if c.Flags != nil {
+		c.Flags.SetOutput(io.Discard)
+		err := c.Flags.Parse(args)
+		if err != nil {
+			return c.flagError(err)
+		}
+		args = c.Flags.Args()
+	}
+	return c.execute(args)
+}
+
+func (c *Command) execute(args []string) error {
+	c.root().current = c
+	if c.isLeaf() {
+		return c.run(args)
+	}
+
+	if len(args) == 0 {
+		c.PrintHelp(os.Stderr)
+		return c.UsageError("command is required")
+	}
+	switch args[0] {
+	case "-h", "-help", "--help":
+		c.PrintHelp(os.Stdout)
+		return flag.ErrHelp
+	}
+	sub := c.Find(args[0])
+	if sub == nil {
+		return c.UsageError("unknown command %q", args[0])
+	}
+	return sub.execute(args[1:])
+}
+
+func (c *Command) run(args []string) error {
+	fs, run := c.flagSet()
+	if c.RawArgs {
+		return run(args)
+	}
+	pos, err := Parse(fs, args)
+	if err != nil {
+		return c.flagError(err)
+	}
+	if before := c.root().Before; before != nil {
+		err = before(c)
+		if err != nil {
+			return err
+		}
+	}
+	return run(pos)
+}
+
+func (c *Command) flagError(err error) error {
+	if errors.Is(err, flag.ErrHelp) {
+		c.PrintHelp(os.Stdout)
+		return err
+	}
+	return c.UsageError("%v", err)
+}
+
+// UsageError returns the usage error of the command
+func (c *Command) UsageError(format string, args ...any) error {
+	return errs.New(errs.Usage, format, args...)
+}
+
+// Parse parses the flags, that may be interspersed with the positional arguments,
+// and returns the positional arguments. Arguments after "--" are not parsed
+func Parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	var pos []string
+	for {
+		err := fs.Parse(args)
+		if err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return pos, nil
+		}
+		// flag package stops at the terminator and consumes it
+		if n := len(args) - len(rest); n > 0 && args[n-1] == "--" {
+			return append(pos, rest...), nil
+		}
+		pos = append(pos, rest[0])
+		args = rest[1:]
+	}
+}
+
+// PrintHelp prints the usage, the description, the subcommands and the flags of the command
+func (c *Command) PrintHelp(w io.Writer) {
+	usage := c.Path()
+	if len(c.Commands) > 0 {
+		usage += " <command>"
+	}
+	if c.isLeaf() && !c.RawArgs {
+		usage += " [flags]"
+	}
+	if c.Args != "" {
+		usage += " " + c.Args
+	}
+	fmt.Fprintf(w, "Usage: %s\n", usage)
+
+	desc := c.Long
+	if desc == "" {
+		desc = c.Short
+	}
+	if desc != "" {
+		fmt.Fprintf(w, "\n%s\n", desc)
+	}
+
+	if len(c.Commands) > 0 {
+		fmt.Fprintf(w, "\nCommands:\n")
+		width := 0
+		for _, sub := range c.Commands {
+			if !sub.Hidden && len(sub.Name) > width {
+				width = len(sub.Name)
+			}
+		}
+		for _, sub := range c.Commands {
+			if !sub.Hidden {
+				fmt.Fprintf(w, "  %-*s  %s\n", width, sub.Name, sub.Short)
+			}
+		}
+	}
+
+	global := c.root().Flags
+	// the command's own flags override the global ones with the same name
+	overridden := make(map[string]bool)
+	if c.isLeaf() && !c.RawArgs {
+		fs, _ := c.flagSet()
+		var own []*flag.Flag
+		fs.VisitAll(func(f *flag.Flag) {
+			if global == nil || global.Lookup(f.Name) == nil || global.Lookup(f.Name).Value != f.Value {
+				own = append(own, f)
+				overridden[f.Name] = true
+			}
+		})
+		if len(own) > 0 {
+			fmt.Fprintf(w, "\nFlags:\n")
+			printFlags(w, own)
+		}
+	}
+	if global != nil {
+		var flags []*flag.Flag
+		global.VisitAll(func(f *flag.Flag) {
+			if !overridden[f.Name] {
+				flags = append(flags, f)
+			}
+		})
+		if len(flags) > 0 {
+			fmt.Fprintf(w, "\nGlobal flags:\n")
+			printFlags(w, flags)
+		}
+	}
+	if len(c.Commands) > 0 {
+		fmt.Fprintf(w, "\nRun '%s <command> --help' for more information on a command.\n", c.Path())
+	}
+}
+
+func printFlags(w io.Writer, flags []*flag.Flag) {
+	sort.Slice(flags, func(i, j int) bool {
+		return flags[i].Name < flags[j].Name
+	})
+	for _, f := range flags {
+		name, usage := flag.UnquoteUsage(f)
+		line := "  --" + f.Name
+		if name != "" {
+			line += " " + name
+		}
+		if f.DefValue != "" && f.DefValue != "false" {
+			usage += fmt.Sprintf(" (default %q)", f.DefValue)
+		}
+		fmt.Fprintf(w, "%-30s %s\n", line, usage)
+	}
+}
+
+// AddHelp adds the command printing the help of the other commands: help [command]...
+func (c *Command) AddHelp() *Command {
+	return c.Add(&Command{
+		Name:    "help",
+		Args:    "[command]...",
+		Short:   "Show help of the command",
+		RawArgs: true,
+		Setup: func(fs *flag.FlagSet) RunFunc {
+			return func(args []string) error {
+				target := c
+				for _, name := range args {
+					target = target.Find(name)
+					if target == nil {
+						return c.UsageError("unknown command %q", name)
+					}
+				}
+				target.PrintHelp(os.Stdout)
+				return flag.ErrHelp
+			}
+		},
+	})
+}
@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+const completeCommand = "__complete"
+
+// boolFlag is implemented by the values of the flags without an argument
+type boolFlag interface {
+	IsBoolFlag() bool
+}
+
+func takesValue(f *flag.Flag) bool {
+	if b, ok := f.Value.(boolFlag); ok && b.IsBoolFlag() {
+		return false
+	}
+	return true
+}
+
+func flagName(word string) (string, bool) {
+	if !strings.HasPrefix(word, "-") || word == "-" || word == "--" {
+		return "", false
+	}
+	return strings.TrimLeft(word, "-"), true
+}
+
+// Candidates returns the candidates for the last word, the words are the arguments after the program name
+func (c *Command) Candidates(words []string) []string {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	cur, words := words[len(words)-1], words[:len(words)-1]
+
+	node := c
+	flags := c.Flags
+	var pos []string
+	var valueOf string
+	for _, word := range words {
+		if valueOf != "" {
+			valueOf = ""
+			continue
+		}
+		if name, ok := flagName(word); ok {
+			if flags != nil && !strings.Contains(name, "=") {
+				if f := flags.Lookup(name); f != nil && takesValue(f) {
+					valueOf = name
+				}
+			}
+			continue
+		}
+		if node.isLeaf() {
+			pos = append(pos, word)
+			continue
+		}
+		node = node.Find(word)
+		if node == nil || node.Hidden {
+			return nil
+		}
+		if node.isLeaf() {
+			flags, _ = node.flagSet()
+		}
+	}
+
+	var candidates []string
+	switch {
+	case valueOf != "":
+		candidates = node.completeValue(valueOf, pos)
+	case strings.HasPrefix(cur, "-"):
+		if flags != nil {
+			flags.VisitAll(func(f *flag.Flag) {
+				candidates = append(candidates, "--"+f.Name)
+			})
+		}
+	case !node.isLeaf():
+		for _, sub := range node.Commands {
+			if !sub.Hidden {
+				candidates = append(candidates, sub.Name)
+			}
+		}
+	default:
+		candidates = node.completeValue("", pos)
+	}
+
+	sort.Strings(candidates)
+	var res []string
+	for i, s := range candidates {
+		if strings.HasPrefix(s, cur) && (i == 0 || candidates[i-1] != s) {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// completeValue asks the command and then its parents for the candidates
+func (c *Command) completeValue(flag string, args []string) []string {
+	for n := c; n != nil; n = n.parent {
+		if n.Complete == nil {
+			continue
+		}
+		if res := n.Complete(flag, args); res != nil {
+			return res
+		}
+	}
+	return nil
+}
+
+// AddCompletion adds the command printing the completion scripts: completion bash|zsh|fish,
+// and the hidden command used by the scripts to get the candidates
+func (c *Command) AddCompletion() *Command {
+	return c.Add(&Command{
+		Name:  "completion",
+		Args:  "bash|zsh|fish",
+		Short: "Print the shell completion script",
+		Long: "Print the shell completion script, e.g.\n" +
+			"  bash: source <(" + c.Name + " completion bash)\n" +
+			"  zsh:  " + c.Name + " completion zsh > \"${fpath[1]}/_" + c.Name + "\"\n" +
+			"  fish: " + c.Name + " completion fish > ~/.config/fish/completions/" + c.Name + ".fish",
+		Setup: func(fs *flag.FlagSet) RunFunc {
+			return func(args []string) error {
+				if len(args) != 1 {
+					return c.Find("completion").UsageError("shell is required")
+				}
+				script, ok := completionScripts[args[0]]
+				if !ok {
+					return c.Find("completion").UsageError("unknown shell %q", args[0])
+				}
+				fmt.Fprintf(os.Stdout, script, c.Name, strings.ReplaceAll(c.Name, "-", "_"))
+				return nil
+			}
+		},
+		Complete: func(flag string, args []string) []string {
+			if flag == "" && len(args) == 0 {
+				return []string{"bash", "zsh", "fish"}
+			}
+			return []string{}
+		},
+	}, &Command{
+		Name:    completeCommand,
+		Hidden:  true,
+		RawArgs: true,
+		Setup: func(fs *flag.FlagSet) RunFunc {
+			return func(args []string) error {
+				for _, s := range c.Candidates(args) {
+					fmt.Fprintln(os.Stdout, s)
+				}
+				return nil
+			}
+		},
+	})
+}
+
+// completionScripts are formatted with the program name and its identifier form
+var completionScripts = map[string]string{
+	"bash": `# bash completion for %[1]s
+_%[2]s() {
+    local IFS=$'\n'
+    COMPREPLY=($(%[1]s ` + completeCommand + ` "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+}
+complete -o default -F _%[2]s %[1]s
+`,
+	"zsh": `#compdef %[1]s
+# zsh completion for %[1]s
+_%[2]s() {
+    local -a candidates
+    candidates=(${(f)"$(%[1]s ` + completeCommand + ` "${(@)words[2,CURRENT]}" 2>/dev/null)"})
+    if (( ${#candidates} )); then
+        compadd -a candidates
+    else
+        _files
+    fi
+}
+compdef _%[2]s %[1]s
+`,
+	"fish": `# fish completion for %[1]s
+complete -c %[1]s -f -a '(%[1]s ` + completeCommand + ` (commandline -opc)[2..-1] (commandline -ct) 2>/dev/null)'
+`,
+}
@@ -1,15 +1,17 @@
 package config
 
 import (
-	"errors"
 	"fmt"
 	"github.com/sajoniks/ue-tools/module-tool/pkg/ue"
-	"gopkg.in/yaml.v3"
-	"os"
-	"strings"
+	"path/filepath"
 )
 
 type AppConfig struct {
+	File string `yaml:"-"`
+
+	// Templates is the directory with user templates, relative to the config file
+	Templates string `yaml:"templates"`
+
 	Project struct {
 		Name      string `yaml:"name"`
 		Copyright struct {
@@ -18,49 +20,234 @@ type AppConfig struct {
 		} `yaml:"copyright"`
 		Category    string `yaml:"category"`
 		Description string `yaml:"description"`
+
+		EngineAssociation string `yaml:"engine_association"`
+
+		Version               int    `yaml:"version"`
+		VersionName           string `yaml:"version_name"`
+		FriendlyName          string `yaml:"friendly_name"`
+		CreatedBy             string `yaml:"created_by"`
+		CreatedByURL          string `yaml:"created_by_url"`
+		DocsURL               string `yaml:"docs_url"`
+		MarketplaceURL        string `yaml:"marketplace_url"`
+		SupportURL            string `yaml:"support_url"`
+		EngineVersion         string `yaml:"engine_version"`
+		CanContainContent     bool   `yaml:"can_contain_content"`
+		IsBetaVersion         bool   `yaml:"is_beta_version"`
+		IsExperimentalVersion bool   `yaml:"is_experimental_version"`
+		Installed             bool   `yaml:"installed"`
+		EnabledByDefault      *bool  `yaml:"enabled_by_default"`
+
+		// plugin folders and files, created along with the plugin
+		Content           bool     `yaml:"content"`
+		DefaultConfig     bool     `yaml:"default_config"`
+		FilterPlugin      bool     `yaml:"filter_plugin"`
+		FilterPluginPaths []string `yaml:"filter_plugin_paths"`
+		Icon              string   `yaml:"icon"`
+
+		Plugins []struct {
+			Name     string `yaml:"name"`
+			Enabled  bool   `yaml:"enabled"`
+			Optional bool   `yaml:"optional"`
+		} `yaml:"plugins"`
 	} `yaml:"project"`
 
-	Modules []struct {
-		Name         string          `yaml:"name"`
-		LoadingPhase ue.LoadingPhase `yaml:"loading_phase"`
-		Type         ue.ModuleType   `yaml:"type"`
+	Targets struct {
+		Types               []ue.TargetType `yaml:"types"`
+		BuildSettings       string          `yaml:"build_settings"`
+		IncludeOrderVersion string          `yaml:"include_order_version"`
+	} `yaml:"targets"`
 
-		Dependencies struct {
-			Public  []string `yaml:"public"`
-			Private []string `yaml:"private"`
-		}
-	} `yaml:"modules"`
+	Modules []ModuleConfig `yaml:"modules"`
 }
 
-func MustLoadProjectConfig(file string) *AppConfig {
-	f, err := os.Open(file)
-	if err != nil {
-		panic(err)
+const KindExternal = "external"
+
+type ModuleConfig struct {
+	Name         string          `yaml:"name"`
+	LoadingPhase ue.LoadingPhase `yaml:"loading_phase"`
+	Type         ue.ModuleType   `yaml:"type"`
+
+	// Kind is empty for the regular modules, or "external" for the third-party library wrappers
+	Kind     string         `yaml:"kind"`
+	External ExternalConfig `yaml:"external"`
+
+	Dependencies struct {
+		Public  []string `yaml:"public"`
+		Private []string `yaml:"private"`
+
+		DynamicallyLoaded        []string                  `yaml:"dynamically_loaded"`
+		PublicIncludePathModules []string                  `yaml:"public_include_path_modules"`
+		Conditional              []ConditionalDependencies `yaml:"conditional"`
 	}
-	defer f.Close()
-	cnf := new(AppConfig)
-	err = yaml.NewDecoder(f).Decode(cnf)
-	if err != nil {
-		panic(err)
+
+	Build BuildConfig `yaml:"build"`
+
+	ApiHeader ApiHeaderConfig `yaml:"api_header"`
+
+	// Vars are passed to the templates of the custom files
+	Vars  map[string]string `yaml:"vars"`
+	Files []FileConfig      `yaml:"files"`
+}
+
+func (mc *ModuleConfig) IsExternal() bool {
+	return mc.Kind == KindExternal
+}
+
+// ExternalConfig describes the third-party library. Paths are relative to the ThirdParty/<Lib> folder
+type ExternalConfig struct {
+	IncludeDirs []string                          `yaml:"include_dirs"`
+	Definitions []string                          `yaml:"definitions"`
+	Platforms   map[string]ExternalPlatformConfig `yaml:"platforms"`
+}
+
+type ExternalPlatformConfig struct {
+	Libraries           []string `yaml:"libraries"`
+	DelayLoadDLLs       []string `yaml:"delay_load_dlls"`
+	RuntimeDependencies []string `yaml:"runtime_dependencies"`
+}
+
+// ApiHeaderConfig describes Public/<Module>API.h (or <Module>Types.h) with the module-wide declarations
+type ApiHeaderConfig struct {
+	// Suffix of the header file name: "API" or "Types"
+	Suffix       string `yaml:"suffix"`
+	LogCategory  bool   `yaml:"log_category"`
+	LogVerbosity string `yaml:"log_verbosity"`
+	StatsGroup   bool   `yaml:"stats_group"`
+}
+
+// FileName returns the name of the header, or empty string if it's not requested
+func (c *ApiHeaderConfig) FileName(moduleName string) string {
+	if c.Suffix == "" && !c.LogCategory && !c.StatsGroup {
+		return ""
 	}
-	err = validateConfig(cnf)
-	if err != nil {
-		panic(err)
+	suffix := c.Suffix
+	if suffix == "" {
+		suffix = "API"
 	}
-	return cnf
+	return moduleName + suffix + ".h"
+}
+
+// BuildConfig are the ModuleRules settings rendered into the Build.cs
+type BuildConfig struct {
+	PrivateIncludePaths  []string `yaml:"private_include_paths"`
+	PublicDefinitions    []string `yaml:"public_definitions"`
+	PCHUsage             string   `yaml:"pch_usage"`
+	PrivatePCHHeaderFile string   `yaml:"private_pch_header_file"`
+	CppStandard          string   `yaml:"cpp_standard"`
+	EnforceIWYU          *bool    `yaml:"enforce_iwyu"`
+	IWYUSupport          string   `yaml:"iwyu_support"`
+	UseUnity             *bool    `yaml:"use_unity"`
+	OptimizeCode         string   `yaml:"optimize_code"`
 }
 
-func validateConfig(cnf *AppConfig) error {
-	if strings.ContainsAny(cnf.Project.Name, "\n\t\r ") {
-		return fmt.Errorf("invalid project name: %q", cnf.Project.Name)
+// ConditionalDependencies are added only when all the conditions are met.
+// Lists of platforms and targets match any of their values
+type ConditionalDependencies struct {
+	Editor    *bool           `yaml:"editor"`
+	Platforms []string        `yaml:"platforms"`
+	Targets   []ue.TargetType `yaml:"targets"`
+
+	Public            []string `yaml:"public"`
+	Private           []string `yaml:"private"`
+	DynamicallyLoaded []string `yaml:"dynamically_loaded"`
+	PublicDefinitions []string `yaml:"public_definitions"`
+}
+
+// FileConfig is a custom file generated in the module directory
+type FileConfig struct {
+	Template string            `yaml:"template"`
+	Path     string            `yaml:"path"`
+	Vars     map[string]string `yaml:"vars"`
+	// Requires are the modules the generated file depends on
+	Requires []string `yaml:"requires"`
+}
+
+// FileRequirements returns the modules required by the custom files, that are not in the public dependencies
+func (mc *ModuleConfig) FileRequirements() []string {
+	var res []string
+	for _, f := range mc.Files {
+		for _, req := range f.Requires {
+			if !contains(mc.Dependencies.Public, req) && !contains(res, req) {
+				res = append(res, req)
+			}
+		}
 	}
-	if len(cnf.Modules) == 0 {
-		return errors.New("want at least 1 module, but 0 was defined")
+	return res
+}
+
+func contains(ls []string, s string) bool {
+	for _, v := range ls {
+		if v == s {
+			return true
+		}
 	}
-	for _, mdl := range cnf.Modules {
-		if mdl.Name == "" || strings.ContainsAny(mdl.Name, "\n\t\r ") {
-			return fmt.Errorf("invalid module name: %q", mdl.Name)
+	return false
+}
+
+func (cnf *AppConfig) Module(name string) *ModuleConfig {
+	for i := range cnf.Modules {
+		if cnf.Modules[i].Name == name {
+			return &cnf.Modules[i]
 		}
 	}
 	return nil
 }
+
+// ManagesPlugins checks, if the config sets the plugin references of the project. Without the plugins key
+// the references are left as they are, the empty list means the project must reference no plugins
+func (cnf *AppConfig) ManagesPlugins() bool {
+	return cnf.Project.Plugins != nil
+}
+
+// TemplatesDir returns the path to the user templates directory, or empty string if it's not set
+func (cnf *AppConfig) TemplatesDir() string {
+	return cnf.ResolvePath(cnf.Templates)
+}
+
+// ResolvePath returns the path relative to the config file
+func (cnf *AppConfig) ResolvePath(p string) string {
+	if p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(filepath.Dir(cnf.File), p)
+}
+
+// PluginDescriptor makes the .uplugin descriptor from the project section
+func (cnf *AppConfig) PluginDescriptor() *ue.PluginFileDescriptor {
+	desc := &ue.PluginFileDescriptor{
+		Version:               cnf.Project.Version,
+		VersionName:           cnf.Project.VersionName,
+		FriendlyName:          cnf.Project.FriendlyName,
+		Description:           cnf.Project.Description,
+		Category:              cnf.Project.Category,
+		CreatedBy:             cnf.Project.CreatedBy,
+		CreatedByURL:          cnf.Project.CreatedByURL,
+		DocsURL:               cnf.Project.DocsURL,
+		MarketplaceURL:        cnf.Project.MarketplaceURL,
+		SupportURL:            cnf.Project.SupportURL,
+		EngineVersion:         cnf.Project.EngineVersion,
+		CanContainContent:     cnf.Project.CanContainContent || cnf.Project.Content,
+		IsBetaVersion:         cnf.Project.IsBetaVersion,
+		IsExperimentalVersion: cnf.Project.IsExperimentalVersion,
+		Installed:             cnf.Project.Installed,
+		EnabledByDefault:      cnf.Project.EnabledByDefault,
+	}
+	if desc.Version == 0 {
+		desc.Version = 1
+	}
+	if desc.VersionName == "" {
+		desc.VersionName = fmt.Sprintf("%d.0", desc.Version)
+	}
+	if desc.FriendlyName == "" {
+		desc.FriendlyName = cnf.Project.Name
+	}
+	for _, pl := range cnf.Project.Plugins {
+		desc.Plugins = append(desc.Plugins, &ue.PluginDescriptor{
+			Name:     pl.Name,
+			Enabled:  pl.Enabled,
+			Optional: pl.Optional,
+		})
+	}
+	return desc
+}
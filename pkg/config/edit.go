@@ -0,0 +1,285 @@
+package config
+
+import (
+	"bytes"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/errs"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/report"
+	"gopkg.in/yaml.v3"
+	"os"
+	"strings"
+)
+
+// EditFile applies the edit to the node tree of the config file and writes it back.
+// Comments are kept, but the file is re-indented with 2 spaces
+func EditFile(file string, edit func(root *yaml.Node) (bool, error)) (bool, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return false, err
+	}
+	var doc yaml.Node
+	err = yaml.Unmarshal(b, &doc)
+	if err != nil {
+		return false, err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return false, errs.New(errs.Config, "%s: empty config", file)
+	}
+	changed, err := edit(doc.Content[0])
+	if err != nil || !changed {
+		return false, err
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	err = enc.Encode(&doc)
+	if err != nil {
+		return false, err
+	}
+	_ = enc.Close()
+	report.Touch(file)
+	return true, os.WriteFile(file, buf.Bytes(), 0644)
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func ensureMappingValue(node *yaml.Node, key string, kind yaml.Kind) *yaml.Node {
+	if v := mappingValue(node, key); v != nil {
+		if v.Kind == yaml.ScalarNode && v.Tag == "!!null" {
+			v.Kind, v.Tag, v.Value = kind, "", ""
+		}
+		return v
+	}
+	v := &yaml.Node{Kind: kind}
+	if kind == yaml.SequenceNode {
+		v.Style = yaml.FlowStyle
+	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, v)
+	return v
+}
+
+func findModuleNode(root *yaml.Node, moduleName string) *yaml.Node {
+	modules := mappingValue(root, "modules")
+	if modules == nil || modules.Kind != yaml.SequenceNode {
+		return nil
+	}
+	for _, mdl := range modules.Content {
+		if name := mappingValue(mdl, "name"); name != nil && name.Value == moduleName {
+			return mdl
+		}
+	}
+	return nil
+}
+
+// insertListItems inserts the items into the text of the existing list, so the rest of the file is untouched.
+// Returns false, if the list can't be edited as text
+func insertListItems(lines []string, list *yaml.Node, items []string) ([]string, bool) {
+	if list.Kind != yaml.SequenceNode || list.Line == 0 {
+		return nil, false
+	}
+	if list.Style&yaml.FlowStyle != 0 {
+		ln := list.Line - 1
+		line := lines[ln]
+		open := list.Column - 1
+		if open >= len(line) || line[open] != '[' {
+			return nil, false
+		}
+		closing := strings.IndexByte(line[open:], ']')
+		if closing < 0 {
+			return nil, false
+		}
+		closing += open
+		inner := strings.TrimRight(line[open+1:closing], " ")
+		if strings.TrimSpace(inner) == "" {
+			lines[ln] = line[:open+1] + strings.Join(items, ", ") + line[closing:]
+		} else {
+			at := open + 1 + len(inner)
+			lines[ln] = line[:at] + ", " + strings.Join(items, ", ") + line[at:]
+		}
+		return lines, true
+	}
+	if len(list.Content) == 0 {
+		return nil, false
+	}
+	last := list.Content[len(list.Content)-1]
+	if last.Kind != yaml.ScalarNode || last.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		return nil, false
+	}
+	ln := last.Line - 1
+	dash := strings.LastIndexByte(lines[ln][:last.Column-1], '-')
+	if dash < 0 {
+		return nil, false
+	}
+	res := make([]string, 0, len(lines)+len(items))
+	res = append(res, lines[:ln+1]...)
+	for _, item := range items {
+		res = append(res, strings.Repeat(" ", dash)+"- "+item)
+	}
+	return append(res, lines[ln+1:]...), true
+}
+
+// AddDependencies adds public or private dependencies to the module in the config file.
+// Returns false, if the module is not in the config, or it already has the dependencies
+func AddDependencies(file, moduleName string, public bool, deps ...string) (bool, error) {
+	key := "private"
+	if public {
+		key = "public"
+	}
+
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return false, err
+	}
+	var doc yaml.Node
+	err = yaml.Unmarshal(b, &doc)
+	if err != nil {
+		return false, err
+	}
+	if len(doc.Content) == 0 {
+		return false, errs.New(errs.Config, "%s: empty config", file)
+	}
+	mdl := findModuleNode(doc.Content[0], moduleName)
+	if mdl == nil {
+		return false, nil
+	}
+	list := mappingValue(mappingValue(mdl, "dependencies"), key)
+	var missing []string
+	for _, dep := range deps {
+		if !hasListItem(list, dep) && !contains(missing, dep) {
+			missing = append(missing, dep)
+		}
+	}
+	if len(missing) == 0 {
+		return false, nil
+	}
+
+	if list != nil {
+		if lines, ok := insertListItems(strings.Split(string(b), "\n"), list, missing); ok {
+			report.Touch(file)
+			return true, os.WriteFile(file, []byte(strings.Join(lines, "\n")), 0644)
+		}
+	}
+
+	return EditFile(file, func(root *yaml.Node) (bool, error) {
+		mdl := findModuleNode(root, moduleName)
+		list := ensureMappingValue(ensureMappingValue(mdl, "dependencies", yaml.MappingNode), key, yaml.SequenceNode)
+		if list.Kind != yaml.SequenceNode {
+			return false, errs.New(errs.Config, "%s: dependencies.%s of the module %q is not a list", file, key, moduleName)
+		}
+		for _, dep := range missing {
+			list.Content = append(list.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: dep})
+		}
+		return true, nil
+	})
+}
+
+func hasListItem(list *yaml.Node, value string) bool {
+	if list == nil {
+		return false
+	}
+	for _, item := range list.Content {
+		if item.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+// removeListItems removes the items from the text of the list.
+// Returns false, if the list can't be edited as text
+func removeListItems(lines []string, list *yaml.Node, items []string) ([]string, bool) {
+	if list.Style&yaml.FlowStyle != 0 {
+		ln := list.Line - 1
+		line := lines[ln]
+		open := list.Column - 1
+		closing := strings.IndexByte(line[open:], ']')
+		if line[open] != '[' || closing < 0 {
+			return nil, false
+		}
+		closing += open
+		var keep []string
+		for _, item := range list.Content {
+			if item.Line != list.Line || item.Kind != yaml.ScalarNode || item.Style != 0 {
+				return nil, false
+			}
+			if !contains(items, item.Value) {
+				keep = append(keep, line[item.Column-1:item.Column-1+len(item.Value)])
+			}
+		}
+		lines[ln] = line[:open+1] + strings.Join(keep, ", ") + line[closing:]
+		return lines, true
+	}
+
+	drop := make(map[int]bool)
+	for _, item := range list.Content {
+		if !contains(items, item.Value) {
+			continue
+		}
+		if item.Kind != yaml.ScalarNode || item.Style != 0 {
+			return nil, false
+		}
+		drop[item.Line-1] = true
+	}
+	res := make([]string, 0, len(lines))
+	for ln, line := range lines {
+		if !drop[ln] {
+			res = append(res, line)
+		}
+	}
+	return res, true
+}
+
+// RemoveDependencies removes public or private dependencies of the module in the config file
+func RemoveDependencies(file, moduleName string, public bool, deps ...string) (bool, error) {
+	key := "private"
+	if public {
+		key = "public"
+	}
+
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return false, err
+	}
+	var doc yaml.Node
+	err = yaml.Unmarshal(b, &doc)
+	if err != nil {
+		return false, err
+	}
+	if len(doc.Content) == 0 {
+		return false, errs.New(errs.Config, "%s: empty config", file)
+	}
+	list := mappingValue(mappingValue(findModuleNode(doc.Content[0], moduleName), "dependencies"), key)
+	found := false
+	for _, dep := range deps {
+		found = found || hasListItem(list, dep)
+	}
+	if !found {
+		return false, nil
+	}
+
+	if lines, ok := removeListItems(strings.Split(string(b), "\n"), list, deps); ok {
+		report.Touch(file)
+		return true, os.WriteFile(file, []byte(strings.Join(lines, "\n")), 0644)
+	}
+	return EditFile(file, func(root *yaml.Node) (bool, error) {
+		list := mappingValue(mappingValue(findModuleNode(root, moduleName), "dependencies"), key)
+		content := list.Content[:0]
+		for _, item := range list.Content {
+			if !contains(deps, item.Value) {
+				content = append(content, item)
+			}
+		}
+		list.Content = content
+		return true, nil
+	})
+}
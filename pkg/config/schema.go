@@ -0,0 +1,123 @@
+package config
+
+import (
+	"github.com/sajoniks/ue-tools/module-tool/pkg/ue"
+	"reflect"
+	"strings"
+)
+
+const schemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// schemaEnums are the string enums of the config, keyed by their Go type
+var schemaEnums = map[reflect.Type]func() []string{
+	reflect.TypeOf(ue.ModuleType(0)):   ue.ModuleTypes,
+	reflect.TypeOf(ue.LoadingPhase(0)): ue.LoadingPhases,
+	reflect.TypeOf(ue.TargetType(0)):   ue.TargetTypes,
+}
+
+// schemaRules refine the generated properties, keyed by the dotted path of the property.
+// Array items are denoted with "[]", e.g. "modules[].name"
+var schemaRules = map[string]func(prop map[string]any){
+	"": func(prop map[string]any) {
+		prop["required"] = []string{"modules"}
+	},
+	"project.name": func(prop map[string]any) {
+		prop["anyOf"] = []any{
+			map[string]any{"const": ""},
+			map[string]any{"pattern": NamePattern},
+		}
+	},
+	"modules": func(prop map[string]any) {
+		prop["minItems"] = 1
+	},
+	"modules[]": func(prop map[string]any) {
+		prop["required"] = []string{"name"}
+	},
+	"modules[].name": func(prop map[string]any) {
+		prop["pattern"] = NamePattern
+	},
+	"modules[].kind": func(prop map[string]any) {
+		prop["enum"] = []string{"", KindExternal}
+	},
+	"modules[].api_header.suffix": func(prop map[string]any) {
+		prop["enum"] = []string{"", "API", "Types"}
+	},
+	"project.icon": func(prop map[string]any) {
+		prop["description"] = `"default" for the bundled icon, or path to the .png file`
+	},
+}
+
+// Schema returns JSON Schema of the config file
+func Schema() map[string]any {
+	schema := typeSchema(reflect.TypeOf(AppConfig{}), "", schemaRules)
+	schema["$schema"] = schemaDraft
+	schema["title"] = "module-tool config"
+	return schema
+}
+
+func yamlName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("yaml")
+	if tag == "-" || !field.IsExported() {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		// yaml.v3 uses the lowercased field name by default
+		name = strings.ToLower(field.Name)
+	}
+	return name, true
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func typeSchema(t reflect.Type, path string, rules map[string]func(prop map[string]any)) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var prop map[string]any
+	if values, ok := schemaEnums[t]; ok {
+		prop = map[string]any{"type": "string", "enum": values()}
+	} else {
+		switch t.Kind() {
+		case reflect.Struct:
+			props := make(map[string]any)
+			for i := 0; i < t.NumField(); i++ {
+				field := t.Field(i)
+				name, ok := yamlName(field)
+				if !ok {
+					continue
+				}
+				props[name] = typeSchema(field.Type, joinPath(path, name), rules)
+			}
+			prop = map[string]any{
+				"type":                 "object",
+				"properties":           props,
+				"additionalProperties": false,
+			}
+		case reflect.Slice, reflect.Array:
+			prop = map[string]any{"type": "array", "items": typeSchema(t.Elem(), path+"[]", rules)}
+		case reflect.Map:
+			prop = map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem(), path+".*", rules)}
+		case reflect.Bool:
+			prop = map[string]any{"type": "boolean"}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			prop = map[string]any{"type": "integer"}
+		case reflect.Float32, reflect.Float64:
+			prop = map[string]any{"type": "number"}
+		default:
+			prop = map[string]any{"type": "string"}
+		}
+	}
+
+	if rule, ok := rules[path]; ok {
+		rule(prop)
+	}
+	return prop
+}
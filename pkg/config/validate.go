@@ -0,0 +1,369 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/errs"
+	"gopkg.in/yaml.v3"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NamePattern is the pattern of the project and module names, they must be valid C# and C++ identifiers
+const NamePattern = `^[A-Za-z_][A-Za-z0-9_]*$`
+
+// Problem is a single issue found in the config file
+type Problem struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s", p.File, p.Line, p.Column, p.Message)
+}
+
+// ValidationError holds all problems found in the config file
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		lines[i] = p.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// LoadOptions are the additional requirements of the command to the config
+type LoadOptions struct {
+	// RequireProjectName reports the empty project name, e.g. when the plugin is created from the config
+	RequireProjectName bool
+}
+
+type validator struct {
+	file     string
+	problems []Problem
+}
+
+func (v *validator) report(node *yaml.Node, format string, args ...any) {
+	p := Problem{File: v.file, Line: 1, Column: 1, Message: fmt.Sprintf(format, args...)}
+	if node != nil {
+		p.Line, p.Column = node.Line, node.Column
+	}
+	v.problems = append(v.problems, p)
+}
+
+func (v *validator) err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	sort.SliceStable(v.problems, func(i, j int) bool {
+		a, b := v.problems[i], v.problems[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return errs.Wrap(errs.Validation, &ValidationError{Problems: v.problems})
+}
+
+// LoadProjectConfig reads and validates the config file.
+// Problems of the file are returned as errs.Validation error wrapping *ValidationError
+func LoadProjectConfig(file string, opts LoadOptions) (*AppConfig, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errs.Wrap(errs.Config, err)
+	}
+	v := &validator{file: file}
+
+	var doc yaml.Node
+	err = yaml.Unmarshal(b, &doc)
+	if err != nil {
+		v.reportYamlError(err)
+		return nil, v.err()
+	}
+	root := resolve(&doc)
+	v.validateNode(root, Schema(), "")
+	v.validateModules(root)
+	if opts.RequireProjectName {
+		name := findKey(findKey(root, "project"), "name")
+		if name == nil || name.Value == "" {
+			v.report(orNode(name, findKey(root, "project")), "project name is required")
+		}
+	}
+	if err = v.err(); err != nil {
+		return nil, err
+	}
+
+	cnf := new(AppConfig)
+	cnf.File = file
+	err = root.Decode(cnf)
+	if err != nil {
+		v.reportYamlError(err)
+		return nil, v.err()
+	}
+	return cnf, nil
+}
+
+var yamlLineRe = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// reportYamlError converts the syntax and decoding errors of yaml package into problems
+func (v *validator) reportYamlError(err error) {
+	var msgs []string
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		msgs = typeErr.Errors
+	} else {
+		msgs = []string{err.Error()}
+	}
+	for _, msg := range msgs {
+		p := Problem{File: v.file, Line: 1, Column: 1, Message: strings.TrimPrefix(msg, "yaml: ")}
+		if m := yamlLineRe.FindStringSubmatch(msg); m != nil {
+			p.Line, _ = strconv.Atoi(m[1])
+			p.Message = m[2]
+		}
+		v.problems = append(v.problems, p)
+	}
+}
+
+func resolve(node *yaml.Node) *yaml.Node {
+	for node != nil {
+		switch node.Kind {
+		case 0:
+			// empty document
+			return nil
+		case yaml.DocumentNode:
+			if len(node.Content) == 0 {
+				return nil
+			}
+			node = node.Content[0]
+		case yaml.AliasNode:
+			node = node.Alias
+		default:
+			return node
+		}
+	}
+	return nil
+}
+
+func findKey(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return resolve(node.Content[i+1])
+		}
+	}
+	return nil
+}
+
+func orNode(nodes ...*yaml.Node) *yaml.Node {
+	for _, n := range nodes {
+		if n != nil {
+			return n
+		}
+	}
+	return nil
+}
+
+func isNull(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!null"
+}
+
+func describe(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "mapping"
+	case yaml.SequenceNode:
+		return "list"
+	}
+	return strconv.Quote(node.Value)
+}
+
+func propertyName(path string) string {
+	if path == "" {
+		return "config"
+	}
+	return path
+}
+
+// validateNode checks the node against the subset of JSON Schema produced by Schema
+func (v *validator) validateNode(node *yaml.Node, schema map[string]any, path string) {
+	node = resolve(node)
+	if node == nil || isNull(node) {
+		if required, ok := schema["required"].([]string); ok && path == "" {
+			for _, key := range required {
+				v.report(nil, "%s is required", key)
+			}
+		}
+		return
+	}
+
+	switch schema["type"] {
+	case "object":
+		if node.Kind != yaml.MappingNode {
+			v.report(node, "%s: want mapping, got %s", propertyName(path), describe(node))
+			return
+		}
+		v.validateMapping(node, schema, path)
+	case "array":
+		if node.Kind != yaml.SequenceNode {
+			v.report(node, "%s: want list, got %s", propertyName(path), describe(node))
+			return
+		}
+		if min, ok := schema["minItems"].(int); ok && len(node.Content) < min {
+			v.report(node, "%s: want at least %d item(s), got %d", path, min, len(node.Content))
+		}
+		items, _ := schema["items"].(map[string]any)
+		for i, item := range node.Content {
+			v.validateNode(item, items, fmt.Sprintf("%s[%d]", path, i))
+		}
+	default:
+		if node.Kind != yaml.ScalarNode {
+			v.report(node, "%s: want %s, got %s", propertyName(path), schema["type"], describe(node))
+			return
+		}
+		v.validateScalar(node, schema, path)
+	}
+}
+
+func (v *validator) validateMapping(node *yaml.Node, schema map[string]any, path string) {
+	props, _ := schema["properties"].(map[string]any)
+	seen := make(map[string]bool)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Value == "<<" {
+			// merge keys are validated by the anchored mapping
+			continue
+		}
+		if seen[key.Value] {
+			v.report(key, "%s: duplicate key %q", propertyName(path), key.Value)
+			continue
+		}
+		seen[key.Value] = true
+
+		var sub map[string]any
+		if props != nil {
+			sub, _ = props[key.Value].(map[string]any)
+		}
+		if sub == nil {
+			sub, _ = schema["additionalProperties"].(map[string]any)
+		}
+		if sub == nil {
+			v.report(key, "%s: unknown field %q", propertyName(path), key.Value)
+			continue
+		}
+		v.validateNode(value, sub, joinPath(path, key.Value))
+	}
+	if required, ok := schema["required"].([]string); ok {
+		for _, key := range required {
+			if !seen[key] {
+				v.report(node, "%s: %s is required", propertyName(path), key)
+			}
+		}
+	}
+}
+
+func (v *validator) validateScalar(node *yaml.Node, schema map[string]any, path string) {
+	switch schema["type"] {
+	case "boolean":
+		if node.Tag != "!!bool" {
+			v.report(node, "%s: want boolean, got %s", path, describe(node))
+		}
+		return
+	case "integer":
+		if node.Tag != "!!int" {
+			v.report(node, "%s: want integer, got %s", path, describe(node))
+		}
+		return
+	}
+
+	if values, ok := schema["enum"].([]string); ok && !contains(values, node.Value) {
+		var valid []string
+		for _, value := range values {
+			if value != "" {
+				valid = append(valid, value)
+			}
+		}
+		v.report(node, "%s: unknown value %q, want one of %s", path, node.Value, strings.Join(valid, ", "))
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		v.validatePattern(node, pattern, path)
+	}
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		// the only use is the optional identifier: empty, or matching the pattern
+		for _, alt := range anyOf {
+			alt := alt.(map[string]any)
+			if c, ok := alt["const"]; ok && c == node.Value {
+				break
+			}
+			if pattern, ok := alt["pattern"].(string); ok {
+				v.validatePattern(node, pattern, path)
+			}
+		}
+	}
+}
+
+func (v *validator) validatePattern(node *yaml.Node, pattern, path string) {
+	if regexp.MustCompile(pattern).MatchString(node.Value) {
+		return
+	}
+	if pattern == NamePattern {
+		v.report(node, "%s: invalid identifier %q", path, node.Value)
+		return
+	}
+	v.report(node, "%s: %q does not match %s", path, node.Value, pattern)
+}
+
+// validateModules reports the problems, that are not covered by the schema
+func (v *validator) validateModules(root *yaml.Node) {
+	modules := findKey(root, "modules")
+	if modules == nil || modules.Kind != yaml.SequenceNode {
+		return
+	}
+	defined := make(map[string]*yaml.Node)
+	for i, mdl := range modules.Content {
+		mdl = resolve(mdl)
+		name := findKey(mdl, "name")
+		if name == nil || name.Kind != yaml.ScalarNode || name.Value == "" {
+			continue
+		}
+		path := fmt.Sprintf("modules[%d]", i)
+		if first, ok := defined[name.Value]; ok {
+			v.report(name, "%s.name: duplicate module name %q, first defined at line %d", path, name.Value, first.Line)
+		} else {
+			defined[name.Value] = name
+		}
+
+		deps := findKey(mdl, "dependencies")
+		lists := []string{"public", "private", "dynamically_loaded", "public_include_path_modules"}
+		v.validateSelfDependency(name.Value, deps, lists, path+".dependencies")
+		if conditional := findKey(deps, "conditional"); conditional != nil && conditional.Kind == yaml.SequenceNode {
+			for j, cond := range conditional.Content {
+				v.validateSelfDependency(name.Value, resolve(cond), lists[:3], fmt.Sprintf("%s.dependencies.conditional[%d]", path, j))
+			}
+		}
+	}
+}
+
+func (v *validator) validateSelfDependency(module string, deps *yaml.Node, lists []string, path string) {
+	for _, key := range lists {
+		list := findKey(deps, key)
+		if list == nil || list.Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, dep := range list.Content {
+			dep = resolve(dep)
+			if dep != nil && dep.Value == module {
+				v.report(dep, "%s.%s: module %q depends on itself", path, key, module)
+			}
+		}
+	}
+}
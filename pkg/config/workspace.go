@@ -0,0 +1,86 @@
+package config
+
+import (
+	"github.com/sajoniks/ue-tools/module-tool/pkg/errs"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"reflect"
+)
+
+// Workspace is the set of projects sharing the plugins, described by one file
+type Workspace struct {
+	File string `yaml:"-"`
+
+	// PluginDirs are the shared plugin directories, they are added to AdditionalPluginDirectories of every project
+	PluginDirs []string           `yaml:"plugin_dirs"`
+	Projects   []WorkspaceProject `yaml:"projects"`
+}
+
+// WorkspaceProject is the project of the workspace. Paths are relative to the workspace file
+type WorkspaceProject struct {
+	// Path is the .uproject file, or directory with this file
+	Path string `yaml:"path"`
+	// Config is the config with the modules of the project
+	Config string `yaml:"config"`
+	// Plugins are the configs of the project plugins, the plugins are created if they are not found
+	Plugins []string `yaml:"plugins"`
+}
+
+var workspaceRules = map[string]func(prop map[string]any){
+	"": func(prop map[string]any) {
+		prop["required"] = []string{"projects"}
+	},
+	"projects": func(prop map[string]any) {
+		prop["minItems"] = 1
+	},
+	"projects[]": func(prop map[string]any) {
+		prop["required"] = []string{"path"}
+	},
+}
+
+// WorkspaceSchema returns JSON Schema of the workspace file
+func WorkspaceSchema() map[string]any {
+	schema := typeSchema(reflect.TypeOf(Workspace{}), "", workspaceRules)
+	schema["$schema"] = schemaDraft
+	schema["title"] = "module-tool workspace"
+	return schema
+}
+
+// LoadWorkspace reads and validates the workspace file
+func LoadWorkspace(file string) (*Workspace, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errs.Wrap(errs.Config, err)
+	}
+	v := &validator{file: file}
+
+	var doc yaml.Node
+	err = yaml.Unmarshal(b, &doc)
+	if err != nil {
+		v.reportYamlError(err)
+		return nil, v.err()
+	}
+	root := resolve(&doc)
+	v.validateNode(root, WorkspaceSchema(), "")
+	if err = v.err(); err != nil {
+		return nil, err
+	}
+
+	ws := new(Workspace)
+	ws.File = file
+	err = root.Decode(ws)
+	if err != nil {
+		v.reportYamlError(err)
+		return nil, v.err()
+	}
+	return ws, nil
+}
+
+// ResolvePath returns the path relative to the workspace file
+func (ws *Workspace) ResolvePath(p string) string {
+	if p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(filepath.Dir(ws.File), p)
+}
@@ -0,0 +1,150 @@
+package cs
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ifBlock is the if statement with braces in the rules constructor
+type ifBlock struct {
+	cond string
+	body block
+}
+
+// normalizeCondition removes the whitespace, so the conditions are compared regardless of the formatting
+func normalizeCondition(cond string) string {
+	return strings.Join(strings.Fields(cond), "")
+}
+
+// SameCondition checks, if the conditions are the same, ignoring the whitespace
+func SameCondition(a, b string) bool {
+	return normalizeCondition(a) == normalizeCondition(b)
+}
+
+// ifBlocks returns the if statements of the rules constructor, that have the body in braces.
+// The else branches and the nested if statements are not included
+func (s *Source) ifBlocks() []ifBlock {
+	b, err := s.constructorBody()
+	if err != nil {
+		return nil
+	}
+	var res []ifBlock
+	for _, loc := range s.statements(b, regexp.MustCompile(`\bif\s*\(`)) {
+		open := loc[1] - 1
+		closing := s.matchBrace(open)
+		if closing < 0 {
+			continue
+		}
+		brace := closing + 1
+		for brace < b.to && strings.ContainsRune(" \t\r\n", rune(s.mask[brace])) {
+			brace++
+		}
+		if brace >= b.to || s.mask[brace] != '{' {
+			continue
+		}
+		end := s.matchBrace(brace)
+		if end < 0 {
+			continue
+		}
+		cond := strings.Join(strings.Fields(s.text[open+1:closing]), " ")
+		res = append(res, ifBlock{cond: cond, body: block{brace + 1, end}})
+	}
+	return res
+}
+
+// blocks returns the bodies of the if statements with the condition, or the constructor body, if the condition is empty
+func (s *Source) blocks(cond string) []block {
+	if cond == "" {
+		b, err := s.constructorBody()
+		if err != nil {
+			return nil
+		}
+		return []block{b}
+	}
+	var res []block
+	for _, ib := range s.ifBlocks() {
+		if SameCondition(ib.cond, cond) {
+			res = append(res, ib.body)
+		}
+	}
+	return res
+}
+
+// Conditions returns the conditions of the if blocks of the rules constructor, e.g. "Target.bBuildEditor"
+func (s *Source) Conditions() []string {
+	var res []string
+	seen := make(map[string]bool)
+	for _, ib := range s.ifBlocks() {
+		if !seen[normalizeCondition(ib.cond)] {
+			seen[normalizeCondition(ib.cond)] = true
+			res = append(res, ib.cond)
+		}
+	}
+	return res
+}
+
+// EntriesIf returns the entries of the property in the if blocks with the condition,
+// the conditions are compared ignoring the whitespace. The empty condition is the rules constructor itself, see Entries
+func (s *Source) EntriesIf(cond, property string) []string {
+	var res []string
+	for _, b := range s.blocks(cond) {
+		res = append(res, s.entries(b, property)...)
+	}
+	return res
+}
+
+// AddIf adds the entries to the property in the first if block with the condition, see Add.
+// If there is no such block, it is added to the end of the rules constructor
+func (s *Source) AddIf(cond, property string, entries ...string) error {
+	if cond == "" {
+		return s.Add(property, entries...)
+	}
+	var missing []string
+	present := s.EntriesIf(cond, property)
+	for _, e := range entries {
+		if !contains(present, e) && !contains(missing, e) {
+			missing = append(missing, e)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	blocks := s.blocks(cond)
+	for _, b := range blocks {
+		if len(s.arrays(b, property)) > 0 {
+			return s.add(b, property, missing)
+		}
+	}
+	if len(blocks) > 0 {
+		return s.add(blocks[0], property, missing)
+	}
+
+	b, err := s.constructorBody()
+	if err != nil {
+		return err
+	}
+	// the block is separated from the previous statement with an empty line
+	at, sep := lastCode(s.mask, b.from, b.to)+1, "\n\n"
+	if at <= b.from {
+		at, sep = b.from, "\n"
+	}
+	indent := lineIndent(s.text, b.to) + "\t"
+	stmt := fmt.Sprintf("%s%sif (%s)\n%s{\n%s}", sep, indent, cond, indent, indent)
+	s.set(s.text[:at] + stmt + s.text[at:])
+	return s.add(s.blocks(cond)[0], property, missing)
+}
+
+// RemoveIf removes the entries of the property from the if blocks with the condition, see Remove
+func (s *Source) RemoveIf(cond, property string, entries ...string) bool {
+	if cond == "" {
+		return s.Remove(property, entries...)
+	}
+	var removed bool
+	// the offsets of the blocks change after the removal, they are found again
+	for i := 0; i < len(s.blocks(cond)); i++ {
+		removed = s.remove(s.blocks(cond)[i], property, entries) || removed
+	}
+	return removed
+}
@@ -0,0 +1,287 @@
+package cs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConditions(t *testing.T) {
+	src := Parse([]byte(`public class Bar : ModuleRules
+{
+	public Bar(ReadOnlyTargetRules Target) : base(Target)
+	{
+		if (Target.bBuildEditor)
+		{
+			if (Target.Platform == UnrealTargetPlatform.Mac) { }
+		}
+		else if (Target.Type == TargetType.Server) { }
+		if (Target.Platform ==
+			UnrealTargetPlatform.Linux) { }
+		if (Target.bBuildEditor) { }
+	}
+}
+`))
+	// the nested if, the else branch and the repeated condition are not listed
+	want := []string{"Target.bBuildEditor", "Target.Platform == UnrealTargetPlatform.Linux"}
+	if got := src.Conditions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Conditions() = %q, want %q", got, want)
+	}
+}
+
+func TestEntriesIf(t *testing.T) {
+	tests := []struct {
+		name     string
+		cond     string
+		property string
+		want     []string
+	}{
+		{
+			name:     "top level",
+			cond:     "",
+			property: "PublicDependencyModuleNames",
+			want:     []string{"Core", "Engine"},
+		},
+		{
+			name:     "if block",
+			cond:     "Target.bBuildEditor",
+			property: "PublicDependencyModuleNames",
+			want:     []string{"UnrealEd"},
+		},
+		{
+			name:     "add statement in if block",
+			cond:     "Target.bBuildEditor",
+			property: "PrivateDependencyModuleNames",
+			want:     []string{"EditorStyle"},
+		},
+		{
+			name:     "if without braces is not a block",
+			cond:     "Target.Platform == UnrealTargetPlatform.Win64",
+			property: "PrivateDependencyModuleNames",
+			want:     nil,
+		},
+		{
+			name:     "unknown condition",
+			cond:     "!Target.bBuildEditor",
+			property: "PublicDependencyModuleNames",
+			want:     nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse([]byte(conditionalBuildCs)).EntriesIf(tt.cond, tt.property)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("EntriesIf(%q, %q) = %q, want %q", tt.cond, tt.property, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddIf(t *testing.T) {
+	tests := []struct {
+		name     string
+		cond     string
+		property string
+		entries  []string
+		want     string
+	}{
+		{
+			name:     "existing block",
+			cond:     "Target.bBuildEditor",
+			property: "PublicDependencyModuleNames",
+			entries:  []string{"UnrealEd", "Slate"},
+			want: `using UnrealBuildTool;
+
+public class Foo : ModuleRules
+{
+	public Foo(ReadOnlyTargetRules Target) : base(Target)
+	{
+		PublicDependencyModuleNames.AddRange(new string[] {
+			"Core",
+			"Engine",
+		});
+
+		if (Target.bBuildEditor)
+		{
+			PublicDependencyModuleNames.AddRange(new string[] {
+				"UnrealEd",
+				"Slate",
+			});
+			PrivateDependencyModuleNames.Add("EditorStyle");
+		}
+		if (Target.Platform == UnrealTargetPlatform.Win64)
+			PrivateDependencyModuleNames.Add("D3D12RHI");
+	}
+}
+`,
+		},
+		{
+			name:     "new statement in existing block",
+			cond:     "Target.bBuildEditor",
+			property: "DynamicallyLoadedModuleNames",
+			entries:  []string{"LevelEditor"},
+			want: `using UnrealBuildTool;
+
+public class Foo : ModuleRules
+{
+	public Foo(ReadOnlyTargetRules Target) : base(Target)
+	{
+		PublicDependencyModuleNames.AddRange(new string[] {
+			"Core",
+			"Engine",
+		});
+
+		if (Target.bBuildEditor)
+		{
+			PublicDependencyModuleNames.AddRange(new string[] {
+				"UnrealEd",
+			});
+			DynamicallyLoadedModuleNames.AddRange(new string[] { "LevelEditor" });
+			PrivateDependencyModuleNames.Add("EditorStyle");
+		}
+		if (Target.Platform == UnrealTargetPlatform.Win64)
+			PrivateDependencyModuleNames.Add("D3D12RHI");
+	}
+}
+`,
+		},
+		{
+			name:     "new block",
+			cond:     "Target.Platform == UnrealTargetPlatform.Win64",
+			property: "PrivateDependencyModuleNames",
+			entries:  []string{"D3D12RHI"},
+			want: `using UnrealBuildTool;
+
+public class Foo : ModuleRules
+{
+	public Foo(ReadOnlyTargetRules Target) : base(Target)
+	{
+		PublicDependencyModuleNames.AddRange(new string[] {
+			"Core",
+			"Engine",
+		});
+
+		if (Target.bBuildEditor)
+		{
+			PublicDependencyModuleNames.AddRange(new string[] {
+				"UnrealEd",
+			});
+			PrivateDependencyModuleNames.Add("EditorStyle");
+		}
+		if (Target.Platform == UnrealTargetPlatform.Win64)
+			PrivateDependencyModuleNames.Add("D3D12RHI");
+
+		if (Target.Platform == UnrealTargetPlatform.Win64)
+		{
+			PrivateDependencyModuleNames.AddRange(new string[] { "D3D12RHI" });
+		}
+	}
+}
+`,
+		},
+		{
+			name:     "condition with other whitespace",
+			cond:     "Target.bBuildEditor ",
+			property: "PrivateDependencyModuleNames",
+			entries:  []string{"EditorStyle"},
+			want:     conditionalBuildCs,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := Parse([]byte(conditionalBuildCs))
+			err := src.AddIf(tt.cond, tt.property, tt.entries...)
+			if err != nil {
+				t.Fatalf("AddIf: %v", err)
+			}
+			if got := src.String(); got != tt.want {
+				t.Errorf("AddIf(%q, %q, %q):\n%s\nwant:\n%s", tt.cond, tt.property, tt.entries, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddIfEmptyConstructor(t *testing.T) {
+	src := Parse([]byte(`public class Foo : ModuleRules
+{
+	public Foo(ReadOnlyTargetRules Target) : base(Target)
+	{
+	}
+}`))
+	err := src.AddIf("Target.bBuildEditor", "PublicDependencyModuleNames", "UnrealEd")
+	if err != nil {
+		t.Fatalf("AddIf: %v", err)
+	}
+	want := `public class Foo : ModuleRules
+{
+	public Foo(ReadOnlyTargetRules Target) : base(Target)
+	{
+		if (Target.bBuildEditor)
+		{
+			PublicDependencyModuleNames.AddRange(new string[] { "UnrealEd" });
+		}
+	}
+}`
+	if got := src.String(); got != want {
+		t.Errorf("AddIf:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRemoveIf(t *testing.T) {
+	tests := []struct {
+		name     string
+		cond     string
+		property string
+		entries  []string
+		removed  bool
+		want     string
+	}{
+		{
+			name:     "if block",
+			cond:     "Target.bBuildEditor",
+			property: "PrivateDependencyModuleNames",
+			entries:  []string{"EditorStyle"},
+			removed:  true,
+			want: `using UnrealBuildTool;
+
+public class Foo : ModuleRules
+{
+	public Foo(ReadOnlyTargetRules Target) : base(Target)
+	{
+		PublicDependencyModuleNames.AddRange(new string[] {
+			"Core",
+			"Engine",
+		});
+
+		if (Target.bBuildEditor)
+		{
+			PublicDependencyModuleNames.AddRange(new string[] {
+				"UnrealEd",
+			});
+		}
+		if (Target.Platform == UnrealTargetPlatform.Win64)
+			PrivateDependencyModuleNames.Add("D3D12RHI");
+	}
+}
+`,
+		},
+		{
+			name:     "top level entries are kept",
+			cond:     "Target.bBuildEditor",
+			property: "PublicDependencyModuleNames",
+			entries:  []string{"Core"},
+			removed:  false,
+			want:     conditionalBuildCs,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := Parse([]byte(conditionalBuildCs))
+			if removed := src.RemoveIf(tt.cond, tt.property, tt.entries...); removed != tt.removed {
+				t.Errorf("RemoveIf(%q, %q, %q) = %v, want %v", tt.cond, tt.property, tt.entries, removed, tt.removed)
+			}
+			if got := src.String(); got != tt.want {
+				t.Errorf("RemoveIf(%q, %q, %q):\n%s\nwant:\n%s", tt.cond, tt.property, tt.entries, got, tt.want)
+			}
+		})
+	}
+}
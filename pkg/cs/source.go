@@ -0,0 +1,492 @@
+package cs
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Source is a C# rules file (.Build.cs, .Target.cs) that is edited in place.
+// All lookups are done on a mask of the text, where comments and string contents
+// are blanked out, so the offsets are valid for the original text
+type Source struct {
+	text string
+	mask string
+}
+
+type span struct {
+	from, to int
+}
+
+// block is the range of the statements inside the braces: the rules constructor body or the body of the if statement in it
+type block struct {
+	from, to int
+}
+
+type literal struct {
+	start, end int
+	value      string
+}
+
+func Parse(src []byte) *Source {
+	s := &Source{}
+	s.set(string(src))
+	return s
+}
+
+func (s *Source) String() string {
+	return s.text
+}
+
+func (s *Source) Bytes() []byte {
+	return []byte(s.text)
+}
+
+func (s *Source) set(text string) {
+	s.text = text
+	s.mask = maskCode(text)
+}
+
+func maskCode(text string) string {
+	mask := []byte(text)
+	blank := func(from, to int) {
+		for i := from; i < to && i < len(mask); i++ {
+			if mask[i] != '\n' {
+				mask[i] = ' '
+			}
+		}
+	}
+	for i := 0; i < len(text); i++ {
+		switch {
+		case strings.HasPrefix(text[i:], "//"):
+			end := strings.IndexByte(text[i:], '\n')
+			if end < 0 {
+				end = len(text) - i
+			}
+			blank(i, i+end)
+			i += end
+		case strings.HasPrefix(text[i:], "/*"):
+			end := strings.Index(text[i+2:], "*/")
+			if end < 0 {
+				end = len(text) - i
+			} else {
+				end += 4
+			}
+			blank(i, i+end)
+			i += end - 1
+		case strings.HasPrefix(text[i:], `@"`):
+			j := i + 2
+			for j < len(text) {
+				if text[j] == '"' {
+					if j+1 < len(text) && text[j+1] == '"' {
+						j += 2
+						continue
+					}
+					break
+				}
+				j++
+			}
+			blank(i+2, j)
+			i = j
+		case text[i] == '"' || text[i] == '\'':
+			q := text[i]
+			j := i + 1
+			for j < len(text) && text[j] != q && text[j] != '\n' {
+				if text[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			blank(i+1, j)
+			i = j
+		}
+	}
+	return string(mask)
+}
+
+func (s *Source) matchBrace(open int) int {
+	depth := 0
+	for i := open; i < len(s.mask); i++ {
+		switch s.mask[i] {
+		case '{', '(':
+			depth++
+		case '}', ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// literals returns string literals in the given range of the text
+func (s *Source) literals(from, to int) []literal {
+	var res []literal
+	for i := from; i < to; i++ {
+		if s.mask[i] != '"' {
+			continue
+		}
+		end := strings.IndexByte(s.mask[i+1:to], '"')
+		if end < 0 {
+			break
+		}
+		end += i + 2
+		raw := s.text[i:end]
+		value, err := strconv.Unquote(raw)
+		if err != nil {
+			value = raw[1 : len(raw)-1]
+		}
+		res = append(res, literal{start: i, end: end, value: value})
+		i = end - 1
+	}
+	return res
+}
+
+func addRangeRegexp(property string) *regexp.Regexp {
+	return regexp.MustCompile(`\b` + regexp.QuoteMeta(property) + `\s*\.\s*AddRange\s*\(\s*new\b[^{;]*\{`)
+}
+
+func addRegexp(property string) *regexp.Regexp {
+	return regexp.MustCompile(`\b` + regexp.QuoteMeta(property) + `\s*\.\s*Add\s*\(\s*"[^"]*"\s*\)\s*;`)
+}
+
+// statementOf checks, if the statement starting at pos is directly in the block: it is not nested
+// into the inner braces and is not the body of the if or else without braces
+func (s *Source) statementOf(b block, pos int) bool {
+	if pos < b.from || pos >= b.to {
+		return false
+	}
+	depth := 0
+	for i := b.from; i < pos; i++ {
+		switch s.mask[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		}
+	}
+	if depth != 0 {
+		return false
+	}
+	prev := lastCode(s.mask, b.from, pos)
+	return prev < 0 || s.mask[prev] == ';' || s.mask[prev] == '}'
+}
+
+// statements returns the matches of the regexp, that start the statements of the block
+func (s *Source) statements(b block, re *regexp.Regexp) [][]int {
+	var res [][]int
+	for _, loc := range re.FindAllStringIndex(s.mask[b.from:b.to], -1) {
+		if s.statementOf(b, b.from+loc[0]) {
+			res = append(res, []int{b.from + loc[0], b.from + loc[1]})
+		}
+	}
+	return res
+}
+
+// arrays returns the ranges of the array initializers (inside the braces) added to the property in the block
+func (s *Source) arrays(b block, property string) [][2]int {
+	var res [][2]int
+	for _, loc := range s.statements(b, addRangeRegexp(property)) {
+		open := loc[1] - 1
+		closing := s.matchBrace(open)
+		if closing < 0 {
+			continue
+		}
+		res = append(res, [2]int{open + 1, closing})
+	}
+	return res
+}
+
+// Entries returns the string values added to the property with AddRange or Add calls.
+// Only the statements of the rules constructor itself are read, the ones in the if blocks are not
+func (s *Source) Entries(property string) []string {
+	b, err := s.constructorBody()
+	if err != nil {
+		return nil
+	}
+	return s.entries(b, property)
+}
+
+func (s *Source) entries(b block, property string) []string {
+	var res []string
+	for _, arr := range s.arrays(b, property) {
+		for _, lit := range s.literals(arr[0], arr[1]) {
+			res = append(res, lit.value)
+		}
+	}
+	for _, loc := range s.statements(b, addRegexp(property)) {
+		for _, lit := range s.literals(loc[0], loc[1]) {
+			res = append(res, lit.value)
+		}
+	}
+	return res
+}
+
+// Has checks, if the property contains the entry in the rules constructor, out of the if blocks
+func (s *Source) Has(property, entry string) bool {
+	return contains(s.Entries(property), entry)
+}
+
+// Value returns the right side of the property assignment, e.g. "TargetType.Editor" for "Type = TargetType.Editor;"
+func (s *Source) Value(property string) (string, bool) {
+	re := regexp.MustCompile(`(?m)^\s*` + regexp.QuoteMeta(property) + `\s*=\s*([^;]+);`)
+	loc := re.FindStringSubmatchIndex(s.mask)
+	if loc == nil {
+		return "", false
+	}
+	return strings.TrimSpace(s.text[loc[2]:loc[3]]), true
+}
+
+func lineStart(text string, pos int) int {
+	return strings.LastIndexByte(text[:pos], '\n') + 1
+}
+
+func lineIndent(text string, pos int) string {
+	start := lineStart(text, pos)
+	end := start
+	for end < len(text) && (text[end] == ' ' || text[end] == '\t') {
+		end++
+	}
+	return text[start:end]
+}
+
+func lastCode(mask string, from, to int) int {
+	for i := to - 1; i >= from; i-- {
+		switch mask[i] {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+func quoteAll(entries []string) []string {
+	res := make([]string, len(entries))
+	for i, e := range entries {
+		res[i] = strconv.Quote(e)
+	}
+	return res
+}
+
+// Add adds entries to the first array of the property in the rules constructor, keeping the formatting of the array.
+// If the property has no array there, new AddRange statement is added to the constructor.
+// The arrays in the if blocks are neither changed, nor make the entries present
+func (s *Source) Add(property string, entries ...string) error {
+	b, err := s.constructorBody()
+	if err != nil {
+		return err
+	}
+	return s.add(b, property, entries)
+}
+
+func (s *Source) add(b block, property string, entries []string) error {
+	present := s.entries(b, property)
+	var missing []string
+	for _, e := range entries {
+		if !contains(present, e) && !contains(missing, e) {
+			missing = append(missing, e)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	arrays := s.arrays(b, property)
+	if len(arrays) == 0 {
+		s.addStatement(b, property, missing)
+		return nil
+	}
+
+	from, to := arrays[0][0], arrays[0][1]
+	last := lastCode(s.mask, from, to)
+	body := s.text[from:to]
+	if !strings.Contains(body, "\n") || (last >= 0 && !strings.Contains(s.text[last:to], "\n")) {
+		// single line array: { "Core", "Engine" }
+		if last < 0 {
+			s.set(s.text[:from] + " " + strings.Join(quoteAll(missing), ", ") + " " + s.text[to:])
+		} else if s.mask[last] == ',' {
+			s.set(s.text[:last+1] + " " + strings.Join(quoteAll(missing), ", ") + "," + s.text[last+1:])
+		} else {
+			s.set(s.text[:last+1] + ", " + strings.Join(quoteAll(missing), ", ") + s.text[last+1:])
+		}
+		return nil
+	}
+
+	var indent string
+	if lits := s.literals(from, to); len(lits) > 0 {
+		indent = lineIndent(s.text, lits[0].start)
+	} else {
+		indent = lineIndent(s.text, to) + "\t"
+	}
+	var sb strings.Builder
+	for _, e := range quoteAll(missing) {
+		sb.WriteString(indent + e + ",\n")
+	}
+
+	if last < 0 {
+		at := lineStart(s.text, to)
+		if strings.TrimSpace(s.text[at:to]) != "" {
+			s.set(s.text[:to] + "\n" + sb.String() + lineIndent(s.text, to) + s.text[to:])
+		} else {
+			s.set(s.text[:at] + sb.String() + s.text[at:])
+		}
+		return nil
+	}
+
+	text := s.text
+	if s.mask[last] != ',' {
+		text = text[:last+1] + "," + text[last+1:]
+	}
+	at := strings.IndexByte(text[last:], '\n') + last + 1
+	s.set(text[:at] + sb.String() + text[at:])
+	return nil
+}
+
+func (s *Source) constructorBody() (block, error) {
+	loc := regexp.MustCompile(`\bbase\s*\(\s*Target\s*\)\s*\{`).FindStringIndex(s.mask)
+	if loc == nil {
+		return block{}, fmt.Errorf("rules constructor was not found")
+	}
+	closing := s.matchBrace(loc[1] - 1)
+	if closing < 0 {
+		return block{}, fmt.Errorf("rules constructor is not closed")
+	}
+	return block{loc[1], closing}, nil
+}
+
+// addStatement adds AddRange statement to the block after its last AddRange statement,
+// or after its last statement, if there is no AddRange
+func (s *Source) addStatement(b block, property string, entries []string) {
+	from, to := b.from, b.to
+	at := -1
+	for _, loc := range s.statements(b, regexp.MustCompile(`\b\w+\s*\.\s*AddRange\s*\(`)) {
+		closing := s.matchBrace(loc[1] - 1)
+		if closing < 0 {
+			continue
+		}
+		end := strings.IndexByte(s.mask[closing:to], ';')
+		if end < 0 {
+			continue
+		}
+		at = closing + end + 1
+	}
+
+	var indent string
+	if at < 0 {
+		at = lastCode(s.mask, from, to) + 1
+		if at <= from {
+			at = from
+			indent = lineIndent(s.text, to) + "\t"
+		}
+	}
+	if indent == "" {
+		indent = lineIndent(s.text, at-1)
+		if at == from || strings.TrimSpace(indent) != "" {
+			indent = lineIndent(s.text, to) + "\t"
+		}
+	}
+
+	stmt := fmt.Sprintf("\n%s%s.AddRange(new string[] { %s });", indent, property, strings.Join(quoteAll(entries), ", "))
+	s.set(s.text[:at] + stmt + s.text[at:])
+}
+
+// Remove removes entries from all arrays of the property and Add statements with these entries
+// in the rules constructor. The entries in the if blocks are kept
+func (s *Source) Remove(property string, entries ...string) bool {
+	b, err := s.constructorBody()
+	if err != nil {
+		return false
+	}
+	return s.remove(b, property, entries)
+}
+
+func (s *Source) remove(b block, property string, entries []string) bool {
+	var spans []span
+
+	for _, arr := range s.arrays(b, property) {
+		for _, lit := range s.literals(arr[0], arr[1]) {
+			if !contains(entries, lit.value) {
+				continue
+			}
+			spans = append(spans, s.entrySpan(lit, arr[0], arr[1]))
+		}
+	}
+	for _, loc := range s.statements(b, addRegexp(property)) {
+		lits := s.literals(loc[0], loc[1])
+		if len(lits) != 1 || !contains(entries, lits[0].value) {
+			continue
+		}
+		spans = append(spans, s.lineSpan(loc[0], loc[1]))
+	}
+	if len(spans) == 0 {
+		return false
+	}
+
+	text := s.text
+	for i := len(spans) - 1; i >= 0; i-- {
+		text = text[:spans[i].from] + text[spans[i].to:]
+	}
+	s.set(text)
+	return true
+}
+
+// lineSpan extends the range to the whole line, if there is nothing else on it
+func (s *Source) lineSpan(from, to int) span {
+	start := lineStart(s.mask, from)
+	end := strings.IndexByte(s.mask[to:], '\n')
+	if end < 0 {
+		end = len(s.mask)
+	} else {
+		end += to
+	}
+	if strings.TrimSpace(s.mask[start:from]) == "" && strings.TrimSpace(s.mask[to:end]) == "" {
+		if end < len(s.text) {
+			end++
+		}
+		return span{start, end}
+	}
+	return span{from, to}
+}
+
+func (s *Source) entrySpan(lit literal, arrFrom, arrTo int) span {
+	to := lit.end
+	for to < arrTo && (s.mask[to] == ' ' || s.mask[to] == '\t') {
+		to++
+	}
+	hasComma := to < arrTo && s.mask[to] == ','
+	if hasComma {
+		to++
+	}
+	line := s.lineSpan(lit.start, to)
+	if line.from != lit.start {
+		return line
+	}
+	if hasComma {
+		for to < arrTo && s.mask[to] == ' ' {
+			to++
+		}
+		return span{lit.start, to}
+	}
+
+	// last entry without a trailing comma: remove the preceding comma instead
+	from := lit.start
+	prev := lastCode(s.mask, arrFrom-1, from)
+	if prev >= 0 && s.mask[prev] == ',' {
+		from = prev
+	} else if prev >= 0 && s.mask[prev] == '{' {
+		from = prev + 1
+	}
+	return span{from, lit.end}
+}
+
+func contains(ls []string, s string) bool {
+	for _, v := range ls {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
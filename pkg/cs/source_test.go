@@ -0,0 +1,305 @@
+package cs
+
+import (
+	"reflect"
+	"testing"
+)
+
+const conditionalBuildCs = `using UnrealBuildTool;
+
+public class Foo : ModuleRules
+{
+	public Foo(ReadOnlyTargetRules Target) : base(Target)
+	{
+		PublicDependencyModuleNames.AddRange(new string[] {
+			"Core",
+			"Engine",
+		});
+
+		if (Target.bBuildEditor)
+		{
+			PublicDependencyModuleNames.AddRange(new string[] {
+				"UnrealEd",
+			});
+			PrivateDependencyModuleNames.Add("EditorStyle");
+		}
+		if (Target.Platform == UnrealTargetPlatform.Win64)
+			PrivateDependencyModuleNames.Add("D3D12RHI");
+	}
+}
+`
+
+func TestEntries(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		property string
+		want     []string
+	}{
+		{
+			name:     "top level array",
+			src:      conditionalBuildCs,
+			property: "PublicDependencyModuleNames",
+			want:     []string{"Core", "Engine"},
+		},
+		{
+			name:     "only conditional entries",
+			src:      conditionalBuildCs,
+			property: "PrivateDependencyModuleNames",
+			want:     nil,
+		},
+		{
+			name: "array and add",
+			src: `public class Foo : ModuleRules { public Foo(ReadOnlyTargetRules Target) : base(Target) {
+	PublicDependencyModuleNames.AddRange(new string[] { "Core" /* "Slate" */ });
+	PublicDependencyModuleNames.Add("UMG"); // PublicDependencyModuleNames.Add("Json");
+} }`,
+			property: "PublicDependencyModuleNames",
+			want:     []string{"Core", "UMG"},
+		},
+		{
+			name:     "no constructor",
+			src:      `PublicDependencyModuleNames.Add("Core");`,
+			property: "PublicDependencyModuleNames",
+			want:     nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse([]byte(tt.src)).Entries(tt.property)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Entries(%q) = %q, want %q", tt.property, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdd(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		property string
+		entries  []string
+		want     string
+	}{
+		{
+			name:     "multiline array",
+			src:      conditionalBuildCs,
+			property: "PublicDependencyModuleNames",
+			entries:  []string{"UMG", "Core"},
+			want: `using UnrealBuildTool;
+
+public class Foo : ModuleRules
+{
+	public Foo(ReadOnlyTargetRules Target) : base(Target)
+	{
+		PublicDependencyModuleNames.AddRange(new string[] {
+			"Core",
+			"Engine",
+			"UMG",
+		});
+
+		if (Target.bBuildEditor)
+		{
+			PublicDependencyModuleNames.AddRange(new string[] {
+				"UnrealEd",
+			});
+			PrivateDependencyModuleNames.Add("EditorStyle");
+		}
+		if (Target.Platform == UnrealTargetPlatform.Win64)
+			PrivateDependencyModuleNames.Add("D3D12RHI");
+	}
+}
+`,
+		},
+		{
+			name:     "entry of the if block is added to the top level",
+			src:      conditionalBuildCs,
+			property: "PublicDependencyModuleNames",
+			entries:  []string{"UnrealEd"},
+			want: `using UnrealBuildTool;
+
+public class Foo : ModuleRules
+{
+	public Foo(ReadOnlyTargetRules Target) : base(Target)
+	{
+		PublicDependencyModuleNames.AddRange(new string[] {
+			"Core",
+			"Engine",
+			"UnrealEd",
+		});
+
+		if (Target.bBuildEditor)
+		{
+			PublicDependencyModuleNames.AddRange(new string[] {
+				"UnrealEd",
+			});
+			PrivateDependencyModuleNames.Add("EditorStyle");
+		}
+		if (Target.Platform == UnrealTargetPlatform.Win64)
+			PrivateDependencyModuleNames.Add("D3D12RHI");
+	}
+}
+`,
+		},
+		{
+			name:     "new statement after the last top level statement",
+			src:      conditionalBuildCs,
+			property: "PrivateDependencyModuleNames",
+			entries:  []string{"EditorStyle", "Slate"},
+			want: `using UnrealBuildTool;
+
+public class Foo : ModuleRules
+{
+	public Foo(ReadOnlyTargetRules Target) : base(Target)
+	{
+		PublicDependencyModuleNames.AddRange(new string[] {
+			"Core",
+			"Engine",
+		});
+		PrivateDependencyModuleNames.AddRange(new string[] { "EditorStyle", "Slate" });
+
+		if (Target.bBuildEditor)
+		{
+			PublicDependencyModuleNames.AddRange(new string[] {
+				"UnrealEd",
+			});
+			PrivateDependencyModuleNames.Add("EditorStyle");
+		}
+		if (Target.Platform == UnrealTargetPlatform.Win64)
+			PrivateDependencyModuleNames.Add("D3D12RHI");
+	}
+}
+`,
+		},
+		{
+			name: "single line array",
+			src: `public class Foo : ModuleRules { public Foo(ReadOnlyTargetRules Target) : base(Target) {
+	PublicDependencyModuleNames.AddRange(new string[] { "Core", "Engine" });
+} }`,
+			property: "PublicDependencyModuleNames",
+			entries:  []string{"UMG"},
+			want: `public class Foo : ModuleRules { public Foo(ReadOnlyTargetRules Target) : base(Target) {
+	PublicDependencyModuleNames.AddRange(new string[] { "Core", "Engine", "UMG" });
+} }`,
+		},
+		{
+			name: "only if block statements",
+			src: `public class Foo : ModuleRules
+{
+	public Foo(ReadOnlyTargetRules Target) : base(Target)
+	{
+		if (Target.bBuildEditor)
+		{
+			PrivateDependencyModuleNames.AddRange(new string[] { "UnrealEd" });
+		}
+	}
+}`,
+			property: "PrivateDependencyModuleNames",
+			entries:  []string{"Core"},
+			want: `public class Foo : ModuleRules
+{
+	public Foo(ReadOnlyTargetRules Target) : base(Target)
+	{
+		if (Target.bBuildEditor)
+		{
+			PrivateDependencyModuleNames.AddRange(new string[] { "UnrealEd" });
+		}
+		PrivateDependencyModuleNames.AddRange(new string[] { "Core" });
+	}
+}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := Parse([]byte(tt.src))
+			err := src.Add(tt.property, tt.entries...)
+			if err != nil {
+				t.Fatalf("Add: %v", err)
+			}
+			if got := src.String(); got != tt.want {
+				t.Errorf("Add(%q, %q):\n%s\nwant:\n%s", tt.property, tt.entries, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddNoConstructor(t *testing.T) {
+	src := Parse([]byte(`public class Foo {}`))
+	if err := src.Add("PublicDependencyModuleNames", "Core"); err == nil {
+		t.Errorf("Add without the rules constructor must fail")
+	}
+}
+
+func TestRemove(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		property string
+		entries  []string
+		removed  bool
+		want     string
+	}{
+		{
+			name:     "top level entry",
+			src:      conditionalBuildCs,
+			property: "PublicDependencyModuleNames",
+			entries:  []string{"Engine"},
+			removed:  true,
+			want: `using UnrealBuildTool;
+
+public class Foo : ModuleRules
+{
+	public Foo(ReadOnlyTargetRules Target) : base(Target)
+	{
+		PublicDependencyModuleNames.AddRange(new string[] {
+			"Core",
+		});
+
+		if (Target.bBuildEditor)
+		{
+			PublicDependencyModuleNames.AddRange(new string[] {
+				"UnrealEd",
+			});
+			PrivateDependencyModuleNames.Add("EditorStyle");
+		}
+		if (Target.Platform == UnrealTargetPlatform.Win64)
+			PrivateDependencyModuleNames.Add("D3D12RHI");
+	}
+}
+`,
+		},
+		{
+			name:     "entries of the if blocks are kept",
+			src:      conditionalBuildCs,
+			property: "PrivateDependencyModuleNames",
+			entries:  []string{"EditorStyle", "D3D12RHI"},
+			removed:  false,
+			want:     conditionalBuildCs,
+		},
+		{
+			name: "add statement and last entry",
+			src: `public class Foo : ModuleRules { public Foo(ReadOnlyTargetRules Target) : base(Target) {
+	PublicDependencyModuleNames.AddRange(new string[] { "Core", "Engine" });
+	PublicDependencyModuleNames.Add("UMG");
+} }`,
+			property: "PublicDependencyModuleNames",
+			entries:  []string{"Engine", "UMG"},
+			removed:  true,
+			want: `public class Foo : ModuleRules { public Foo(ReadOnlyTargetRules Target) : base(Target) {
+	PublicDependencyModuleNames.AddRange(new string[] { "Core" });
+} }`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := Parse([]byte(tt.src))
+			if removed := src.Remove(tt.property, tt.entries...); removed != tt.removed {
+				t.Errorf("Remove(%q, %q) = %v, want %v", tt.property, tt.entries, removed, tt.removed)
+			}
+			if got := src.String(); got != tt.want {
+				t.Errorf("Remove(%q, %q):\n%s\nwant:\n%s", tt.property, tt.entries, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+// Package errs defines the kinds of the tool errors. Each kind has its own exit code:
+//
+//	0  success
+//	1  internal error, a bug of the tool
+//	2  usage: unknown command, missing or invalid arguments
+//	3  config: the config file can't be read or edited
+//	4  validation: the config or the given values are invalid
+//	5  project not found: no .uproject or .uplugin file at the given path
+//	6  conflict: the file, module, plugin or target already exists
+//	7  io: failed to read or write the project files
+//	8  drift: the project diverges from the config
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+type Kind int
+
+const (
+	Internal Kind = iota + 1
+	Usage
+	Config
+	Validation
+	ProjectNotFound
+	Conflict
+	IO
+	Drift
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Internal:
+		return "internal"
+	case Usage:
+		return "usage"
+	case Config:
+		return "config"
+	case Validation:
+		return "validation"
+	case ProjectNotFound:
+		return "project_not_found"
+	case Conflict:
+		return "conflict"
+	case IO:
+		return "io"
+	case Drift:
+		return "drift"
+	}
+	return ""
+}
+
+// ExitCode returns the process exit code of the error kind
+func (k Kind) ExitCode() int {
+	return int(k)
+}
+
+// Error is the error of the known kind
+type Error struct {
+	Kind Kind
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New returns the error of the kind with the formatted message, %w verb is supported
+func New(kind Kind, format string, args ...any) error {
+	return &Error{Kind: kind, Err: fmt.Errorf(format, args...)}
+}
+
+// Wrap sets the kind of the error, unless it already has one
+func Wrap(kind Kind, err error) error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return err
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+// KindOf returns the kind of the error. File system errors without a kind are IO errors
+func KindOf(err error) Kind {
+	if err == nil {
+		return 0
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	var pathErr *fs.PathError
+	var linkErr *os.LinkError
+	if errors.As(err, &pathErr) || errors.As(err, &linkErr) {
+		return IO
+	}
+	return Internal
+}
+
+func Is(err error, kind Kind) bool {
+	return KindOf(err) == kind
+}
@@ -0,0 +1,134 @@
+package factory
+
+import (
+	"github.com/sajoniks/ue-tools/module-tool/pkg/errs"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/ue"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// CreateClass makes the descriptor of a new class in the module. The parent class is taken
+// from the preset, if it's not given explicitly. The header of the parent class, if it's not given
+// and the parent is not the one of the preset, is searched in the Public folders of the project modules
+func CreateClass(projectFile *ue.ProjectFileDescriptor, moduleName, className, parent, presetName, include, dir string) (*ue.ClassDescriptor, error) {
+	if className == "" || strings.ContainsAny(className, "\n\t\r ./\\") {
+		return nil, errs.New(errs.Validation, "invalid class name: %q", className)
+	}
+	found := false
+	for _, mdl := range projectFile.Modules {
+		if mdl.Name == moduleName {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, errs.New(errs.Validation, "module %q is not in the project", moduleName)
+	}
+
+	var preset *ue.ClassPreset
+	if presetName != "" {
+		preset = ue.FindClassPreset(presetName)
+		if preset == nil {
+			return nil, errs.New(errs.Validation, "unknown class preset: %q", presetName)
+		}
+	} else if parent != "" {
+		preset = ue.FindClassPresetByParent(parent)
+	} else {
+		return nil, errs.New(errs.Validation, "either parent class or preset must be given")
+	}
+
+	dir = path.Clean(filepath.ToSlash(dir))
+	if dir == "." {
+		dir = ""
+	}
+	if strings.HasPrefix(dir, "..") || path.IsAbs(dir) {
+		return nil, errs.New(errs.Validation, "class folder %q is outside of the module", dir)
+	}
+
+	class := &ue.ClassDescriptor{
+		Module: moduleName,
+		Name:   className,
+		Parent: parent,
+		Dir:    dir,
+	}
+	if preset != nil {
+		class.Preset = preset.Name
+		class.Specifiers = preset.Specifiers
+		if class.Parent == "" {
+			class.Parent = preset.Parent
+		}
+		// the header and the modules of the preset are of its own parent
+		if class.Parent == preset.Parent {
+			class.Include = preset.Include
+			class.Modules = append(class.Modules, preset.Modules...)
+		}
+	}
+	if len(class.Parent) < 2 || !strings.HasPrefix(class.Parent, "A") && !strings.HasPrefix(class.Parent, "U") {
+		return nil, errs.New(errs.Validation, "parent class %q must be UObject or AActor derived", class.Parent)
+	}
+	if include != "" {
+		class.Include = include
+	}
+	if class.Include == "" {
+		header, mdl, err := findClassHeader(projectFile, class.Parent)
+		if err != nil {
+			return nil, err
+		}
+		class.Include = header
+		if mdl != moduleName && !contains(class.Modules, mdl) {
+			class.Modules = append(class.Modules, mdl)
+		}
+	}
+	if mdl, ok := ue.ClassModule(class.Parent); ok && !contains(class.Modules, mdl) {
+		class.Modules = append(class.Modules, mdl)
+	}
+
+	for _, p := range []string{
+		filepath.Join(projectFile.ModulePublic(moduleName), filepath.FromSlash(class.HeaderInclude())),
+		filepath.Join(projectFile.ModulePrivate(moduleName), filepath.FromSlash(class.SourceFile())),
+	} {
+		if _, err := os.Stat(p); err == nil {
+			return nil, errs.New(errs.Conflict, "file %s already exists", p)
+		}
+	}
+	return class, nil
+}
+
+// findClassHeader finds the header of the class in the Public folders of the project modules by the name without the prefix,
+// returns the include path relative to the Public folder and the module
+func findClassHeader(projectFile *ue.ProjectFileDescriptor, class string) (string, string, error) {
+	name := class[1:] + ".h"
+	var found []string
+	var include, module string
+	for _, mdl := range projectFile.Modules {
+		public := projectFile.ModulePublic(mdl.Name)
+		_ = filepath.WalkDir(public, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || d.Name() != name {
+				return nil
+			}
+			rel, _ := filepath.Rel(public, p)
+			include, module = filepath.ToSlash(rel), mdl.Name
+			found = append(found, p)
+			return nil
+		})
+	}
+	switch len(found) {
+	case 0:
+		return "", "", errs.New(errs.Validation, "header of the parent class %s was not found in the project modules, use --include to set it", class)
+	case 1:
+		return include, module, nil
+	}
+	return "", "", errs.New(errs.Validation, "parent class %s has several headers: %s, use --include to choose one", class, strings.Join(found, ", "))
+}
+
+func contains(ls []string, s string) bool {
+	for _, v := range ls {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
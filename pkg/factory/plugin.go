@@ -1,18 +1,18 @@
 package factory
 
 import (
-	"fmt"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/errs"
 	"github.com/sajoniks/ue-tools/module-tool/pkg/ue"
 	"path/filepath"
 )
 
 func CreateModule(projectFile *ue.ProjectFileDescriptor, moduleName string) (*ue.ProjectModuleDescriptor, error) {
 	if moduleName == "" {
-		return nil, fmt.Errorf("empty plugin name")
+		return nil, errs.New(errs.Validation, "empty module name")
 	}
 	for _, mdl := range projectFile.Modules {
 		if mdl.Name == moduleName {
-			return nil, fmt.Errorf("module is already added to the project")
+			return nil, errs.New(errs.Conflict, "module is already added to the project")
 		}
 	}
 
@@ -25,16 +25,16 @@ func CreateModule(projectFile *ue.ProjectFileDescriptor, moduleName string) (*ue
 	return mdl, nil
 }
 
-func CreatePlugin(projectFile *ue.ProjectFileDescriptor, pluginName string, enable bool) (*ue.ProjectFileDescriptor, error) {
+func CreatePlugin(projectFile *ue.ProjectFileDescriptor, pluginName string, info *ue.PluginFileDescriptor, enable bool) (*ue.ProjectFileDescriptor, error) {
 	if projectFile.IsPlugin {
-		return nil, fmt.Errorf("can't create plugin for plugin")
+		return nil, errs.New(errs.Validation, "can't create plugin for plugin")
 	}
 	if pluginName == "" {
-		return nil, fmt.Errorf("empty plugin name")
+		return nil, errs.New(errs.Validation, "empty plugin name")
 	}
 	for _, pl := range projectFile.Plugins {
 		if pl.Name == pluginName {
-			return nil, fmt.Errorf("plugin is already added to the project")
+			return nil, errs.New(errs.Conflict, "plugin is already added to the project")
 		}
 	}
 
@@ -42,13 +42,24 @@ func CreatePlugin(projectFile *ue.ProjectFileDescriptor, pluginName string, enab
 	// 2. create default module
 	// 3. modify project file descriptor (link new plugin)
 
+	if info == nil {
+		info = &ue.PluginFileDescriptor{
+			Version:      1,
+			VersionName:  "1.0",
+			FriendlyName: pluginName,
+		}
+	}
 	pluginDesc := ue.ProjectFileDescriptor{
 		IsPlugin:          true,
+		Plugin:            info,
 		ProjectPath:       filepath.Join(projectFile.ProjectPath, "Plugins", pluginName),
 		ProjectFileName:   pluginName + ".uplugin",
 		ProjectName:       pluginName,
 		EngineAssociation: projectFile.EngineAssociation,
 		FileVersion:       projectFile.FileVersion,
+		Category:          info.Category,
+		Description:       info.Description,
+		Plugins:           info.Plugins,
 	}
 	_, err := CreateModule(&pluginDesc, pluginName)
 	if err != nil {
@@ -0,0 +1,34 @@
+package factory
+
+import (
+	"github.com/sajoniks/ue-tools/module-tool/pkg/errs"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/ue"
+	"os"
+	"path/filepath"
+)
+
+func CreateProject(dir, projectName, engineAssociation string) (*ue.ProjectFileDescriptor, error) {
+	if projectName == "" {
+		return nil, errs.New(errs.Validation, "empty project name")
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	existing, _ := filepath.Glob(filepath.Join(dir, "*.uproject"))
+	if len(existing) > 0 {
+		return nil, errs.New(errs.Conflict, "project already exists: %s", existing[0])
+	}
+	if _, err = os.Stat(filepath.Join(dir, "Source")); err == nil {
+		return nil, errs.New(errs.Conflict, "directory %s already contains sources", dir)
+	}
+
+	projectFile := &ue.ProjectFileDescriptor{
+		ProjectPath:       dir,
+		ProjectFileName:   projectName + ".uproject",
+		ProjectName:       projectName,
+		EngineAssociation: engineAssociation,
+	}
+	projectFile.Touch()
+	return projectFile, nil
+}
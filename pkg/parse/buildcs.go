@@ -0,0 +1,61 @@
+package parse
+
+import (
+	"github.com/sajoniks/ue-tools/module-tool/pkg/cs"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/errs"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/report"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/ue"
+	"os"
+	"path/filepath"
+)
+
+const (
+	PublicDependencies  = "PublicDependencyModuleNames"
+	PrivateDependencies = "PrivateDependencyModuleNames"
+)
+
+func BuildCsPath(projectFile *ue.ProjectFileDescriptor, moduleName string) string {
+	return filepath.Join(projectFile.ModuleSources(moduleName), moduleName+".Build.cs")
+}
+
+// ReadBuildCs reads the Build.cs file of the module for in-place editing
+func ReadBuildCs(projectFile *ue.ProjectFileDescriptor, moduleName string) (*cs.Source, error) {
+	b, err := os.ReadFile(BuildCsPath(projectFile, moduleName))
+	if err != nil {
+		return nil, err
+	}
+	return cs.Parse(b), nil
+}
+
+// EditBuildCs applies the edit to the Build.cs file of the module and writes it, if it was changed
+func EditBuildCs(projectFile *ue.ProjectFileDescriptor, moduleName string, edit func(src *cs.Source) error) (bool, error) {
+	src, err := ReadBuildCs(projectFile, moduleName)
+	if err != nil {
+		return false, err
+	}
+	before := src.String()
+	err = edit(src)
+	if err != nil {
+		return false, errs.New(errs.Validation, "%s: %w", BuildCsPath(projectFile, moduleName), err)
+	}
+	if before == src.String() {
+		return false, nil
+	}
+	report.Touch(BuildCsPath(projectFile, moduleName))
+	return true, os.WriteFile(BuildCsPath(projectFile, moduleName), src.Bytes(), 0644)
+}
+
+// AddModuleDependencies adds the dependencies to the Build.cs of the module, keeping its formatting
+func AddModuleDependencies(projectFile *ue.ProjectFileDescriptor, moduleName, property string, deps ...string) (bool, error) {
+	return EditBuildCs(projectFile, moduleName, func(src *cs.Source) error {
+		return src.Add(property, deps...)
+	})
+}
+
+// RemoveModuleDependencies removes the dependencies from the Build.cs of the module, keeping its formatting
+func RemoveModuleDependencies(projectFile *ue.ProjectFileDescriptor, moduleName, property string, deps ...string) (bool, error) {
+	return EditBuildCs(projectFile, moduleName, func(src *cs.Source) error {
+		src.Remove(property, deps...)
+		return nil
+	})
+}
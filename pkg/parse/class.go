@@ -0,0 +1,41 @@
+package parse
+
+import (
+	"github.com/sajoniks/ue-tools/module-tool/pkg/printer"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/ue"
+	"io"
+	"path/filepath"
+)
+
+// WriteClass writes the header of the class to the module's Public folder and the source to the Private folder.
+// Returns the paths of the written files
+func WriteClass(projectFile *ue.ProjectFileDescriptor, class *ue.ClassDescriptor, copyright string) ([]string, error) {
+	ctx := printer.ClassCtx{
+		Copyright:     copyright,
+		ModuleApi:     ue.ApiMacro(class.Module),
+		ClassName:     class.Name,
+		FullName:      class.FullName(),
+		Parent:        class.Parent,
+		ParentInclude: class.Include,
+		HeaderInclude: class.HeaderInclude(),
+		Preset:        class.Preset,
+		Specifiers:    class.Specifiers,
+	}
+
+	header := filepath.Join(projectFile.ModulePublic(class.Module), filepath.FromSlash(class.HeaderInclude()))
+	err := writeNewFile(header, func(w io.Writer) error {
+		return printer.PrintClassHeader(ctx, w)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	source := filepath.Join(projectFile.ModulePrivate(class.Module), filepath.FromSlash(class.SourceFile()))
+	err = writeNewFile(source, func(w io.Writer) error {
+		return printer.PrintClassCpp(ctx, w)
+	})
+	if err != nil {
+		return []string{header}, err
+	}
+	return []string{header, source}, nil
+}
@@ -0,0 +1,144 @@
+package parse
+
+import (
+	"github.com/sajoniks/ue-tools/module-tool/pkg/errs"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/report"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/ue"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	projectExt = ".uproject"
+	pluginExt  = ".uplugin"
+)
+
+// Location is the project and the plugin found by the discovery
+type Location struct {
+	// Project is the .uproject, nil if the plugin is not inside a project
+	Project *ue.ProjectFileDescriptor
+	// Plugin is the .uplugin, if the search started inside a plugin
+	Plugin *ue.ProjectFileDescriptor
+}
+
+// Target returns the descriptor the commands work on: the plugin, if the search started inside one, otherwise the project
+func (l *Location) Target() *ue.ProjectFileDescriptor {
+	if l.Plugin != nil {
+		return l.Plugin
+	}
+	return l.Project
+}
+
+// descriptorFiles returns the .uproject and .uplugin files in the directory
+func descriptorFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		switch filepath.Ext(entry.Name()) {
+		case projectExt, pluginExt:
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return files, nil
+}
+
+func ambiguityError(dir string, files []string) error {
+	names := make([]string, len(files))
+	for i, f := range files {
+		names[i] = filepath.Base(f)
+	}
+	return errs.New(errs.Validation, "%s contains several descriptors: %s, use --project to choose one", dir, strings.Join(names, ", "))
+}
+
+// findDescriptor returns the single descriptor file in the directory, or an empty string, if there are none
+func findDescriptor(dir string) (string, error) {
+	files, err := descriptorFiles(dir)
+	if err != nil {
+		return "", err
+	}
+	switch len(files) {
+	case 0:
+		return "", nil
+	case 1:
+		return files[0], nil
+	}
+	return "", ambiguityError(dir, files)
+}
+
+// readDescriptorIn reads the single descriptor in the directory, returns nil, if there are none
+func readDescriptorIn(dir string) (*ue.ProjectFileDescriptor, error) {
+	file, err := findDescriptor(dir)
+	if err != nil || file == "" {
+		return nil, err
+	}
+	return ReadProjectFile(file)
+}
+
+// DiscoverProject walks up from the directory to the nearest .uproject or .uplugin.
+// If the nearest one is a plugin, the walk continues to find the project of the plugin.
+// The errors above the plugin don't fail the discovery, the plugin is returned without the project then
+func DiscoverProject(dir string) (*Location, error) {
+	start, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	loc := new(Location)
+	for dir := start; ; {
+		desc, err := readDescriptorIn(dir)
+		if err != nil {
+			if loc.Plugin == nil {
+				return nil, err
+			}
+			report.Warn("project of the plugin %s was not found: %v", loc.Plugin.ProjectName, err)
+			return loc, nil
+		}
+		if desc != nil && !desc.IsPlugin {
+			loc.Project = desc
+			return loc, nil
+		}
+		if desc != nil && loc.Plugin == nil {
+			loc.Plugin = desc
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	if loc.Plugin == nil {
+		return nil, errs.New(errs.ProjectNotFound, "no %s or %s file was found in %s or its parent directories", projectExt, pluginExt, start)
+	}
+	return loc, nil
+}
+
+// FindProject reads the descriptor at the path (file, or directory with the file),
+// or discovers it from the working directory, if the path is empty.
+// The project of the plugin is searched in the parent directories of the plugin
+func FindProject(path string) (*Location, error) {
+	if path == "" {
+		return DiscoverProject(".")
+	}
+	desc, err := ReadProjectFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if !desc.IsPlugin {
+		return &Location{Project: desc}, nil
+	}
+	loc, err := DiscoverProject(filepath.Dir(desc.ProjectPath))
+	if err != nil {
+		if errs.Is(err, errs.ProjectNotFound) {
+			return &Location{Plugin: desc}, nil
+		}
+		return nil, err
+	}
+	return &Location{Project: loc.Project, Plugin: desc}, nil
+}
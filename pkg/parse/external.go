@@ -0,0 +1,69 @@
+package parse
+
+import (
+	"github.com/sajoniks/ue-tools/module-tool/pkg/config"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/errs"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/printer"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/ue"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// WriteExternalModule writes the ThirdParty/<Lib> folder layout and the Build.cs of ModuleType.External.
+// External modules are not added to the descriptor, other modules reference them as dependencies
+func WriteExternalModule(projectFile *ue.ProjectFileDescriptor, mc *config.ModuleConfig, copyright string) error {
+	root := projectFile.ThirdPartySources(mc.Name)
+	buildCs := filepath.Join(root, mc.Name+".Build.cs")
+	if _, err := os.Stat(buildCs); err == nil {
+		return errs.New(errs.Conflict, "external module %s already exists", buildCs)
+	}
+
+	ext := mc.External
+	if len(ext.IncludeDirs) == 0 {
+		ext.IncludeDirs = []string{"include"}
+	}
+	ctx := printer.ExternalBuildFileCtx{
+		Copyright:   copyright,
+		ModuleName:  mc.Name,
+		IncludeDirs: ext.IncludeDirs,
+		Definitions: ext.Definitions,
+	}
+
+	dirs := append([]string(nil), ext.IncludeDirs...)
+	platforms := make([]string, 0, len(ext.Platforms))
+	for name := range ext.Platforms {
+		platforms = append(platforms, name)
+	}
+	sort.Strings(platforms)
+	for _, name := range platforms {
+		pl := ext.Platforms[name]
+		ctx.Platforms = append(ctx.Platforms, printer.ExternalPlatformCtx{
+			Name:                name,
+			Libraries:           pl.Libraries,
+			DelayLoadDLLs:       pl.DelayLoadDLLs,
+			RuntimeDependencies: pl.RuntimeDependencies,
+		})
+		for _, p := range append(pl.Libraries, pl.RuntimeDependencies...) {
+			dirs = append(dirs, path.Dir(filepath.ToSlash(p)))
+		}
+	}
+
+	for _, dir := range dirs {
+		rel := path.Clean(filepath.ToSlash(dir))
+		if path.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, "../") {
+			return errs.New(errs.Validation, "path %q is outside of the %s folder", dir, root)
+		}
+		err := os.MkdirAll(filepath.Join(root, filepath.FromSlash(rel)), 0755)
+		if err != nil {
+			return err
+		}
+	}
+
+	return writeNewFile(buildCs, func(w io.Writer) error {
+		return printer.PrintExternalBuildCs(ctx, w)
+	})
+}
@@ -0,0 +1,86 @@
+package parse
+
+import (
+	"fmt"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/config"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/errs"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/printer"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/report"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/ue"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// resolveTemplate finds the template of the custom file among the loaded templates,
+// in the templates directory, or relative to the config file
+func resolveTemplate(name string, cnf *config.AppConfig) (string, error) {
+	if printer.HasTemplate(name) {
+		return name, nil
+	}
+	candidates := []string{cnf.ResolvePath(name)}
+	if dir := cnf.TemplatesDir(); dir != "" && !filepath.IsAbs(name) {
+		candidates = append([]string{filepath.Join(dir, name)}, candidates...)
+	}
+	for _, p := range candidates {
+		if _, err := os.Stat(p); err == nil {
+			return printer.LoadTemplateFile(p)
+		}
+	}
+	return "", errs.New(errs.Config, "template %q was not found", name)
+}
+
+func mergeVars(vars ...map[string]string) map[string]string {
+	res := make(map[string]string)
+	for _, v := range vars {
+		for key, val := range v {
+			res[key] = val
+		}
+	}
+	return res
+}
+
+func writeModuleFiles(projectFile *ue.ProjectFileDescriptor, module *ue.ProjectModuleDescriptor, cnf *config.AppConfig) error {
+	mc := cnf.Module(module.Name)
+	if mc == nil {
+		return fmt.Errorf("module not found")
+	}
+	moduleDir := projectFile.ModuleSources(module.Name)
+	for _, file := range mc.Files {
+		tplName, err := resolveTemplate(file.Template, cnf)
+		if err != nil {
+			return err
+		}
+		ctx := printer.UserFileCtx{
+			Copyright:   cnf.Project.Copyright.Text,
+			ProjectName: projectFile.ProjectName,
+			ModuleName:  module.Name,
+			ModuleApi:   ue.ApiMacro(module.Name),
+			Vars:        mergeVars(mc.Vars, file.Vars),
+		}
+		rel, err := printer.ExpandPath(file.Path, ctx)
+		if err != nil {
+			return errs.New(errs.Validation, "invalid path of the file %q: %w", file.Path, err)
+		}
+		p := filepath.Join(moduleDir, filepath.FromSlash(rel))
+		if r, err := filepath.Rel(moduleDir, p); err != nil || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+			return errs.New(errs.Validation, "file %q is outside of the module directory", rel)
+		}
+
+		err = os.MkdirAll(filepath.Dir(p), 0755)
+		if err != nil {
+			return err
+		}
+		report.Touch(p)
+		f, err := os.Create(p)
+		if err != nil {
+			return err
+		}
+		err = printer.PrintUserFile(tplName, ctx, f)
+		_ = f.Close()
+		if err != nil {
+			return errs.New(errs.Config, "%s: %w", p, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package parse
+
+import (
+	"errors"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/errs"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/ue"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PluginInfo is the plugin found in the project
+type PluginInfo struct {
+	// Name is the name of the .uplugin file, it may differ from the name of the folder
+	Name string
+	// Path is the path of the .uplugin file
+	Path    string
+	Modules []string
+	// Enabled is the state of the plugin in the project: the entry of the .uproject, or EnabledByDefault of the plugin
+	Enabled    bool
+	Descriptor *ue.ProjectFileDescriptor
+	// Duplicates are the paths of the other .uplugin files with the same name, the engine refuses to load such plugin
+	Duplicates []string
+}
+
+// PluginIndex is the plugins of the project by their names
+type PluginIndex map[string]*PluginInfo
+
+// Names returns the sorted names of the plugins
+func (idx PluginIndex) Names() []string {
+	names := make([]string, 0, len(idx))
+	for name := range idx {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Find returns the plugin by its name, the names are compared ignoring the case, as the engine does
+func (idx PluginIndex) Find(name string) *PluginInfo {
+	if info, ok := idx[name]; ok {
+		return info
+	}
+	for key, info := range idx {
+		if strings.EqualFold(key, name) {
+			return info
+		}
+	}
+	return nil
+}
+
+func contains(ls []string, s string) bool {
+	for _, v := range ls {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// pluginEnabled returns the state of the plugin in the project
+func pluginEnabled(project *ue.ProjectFileDescriptor, plugin *ue.ProjectFileDescriptor) bool {
+	for _, ref := range project.Plugins {
+		if strings.EqualFold(ref.Name, plugin.ProjectName) {
+			return ref.Enabled
+		}
+	}
+	if plugin.Plugin != nil && plugin.Plugin.EnabledByDefault != nil {
+		return *plugin.Plugin.EnabledByDefault
+	}
+	return true
+}
+
+// scanPlugins walks the directory and adds the plugins to the index.
+// The walk doesn't go into the folders of the found plugins. The folders and the plugins,
+// that can't be read, are skipped, their errors are returned
+func scanPlugins(project *ue.ProjectFileDescriptor, dir string, idx PluginIndex) []error {
+	var problems []error
+	_ = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p != dir || !errors.Is(err, fs.ErrNotExist) {
+				problems = append(problems, err)
+			}
+			if p == dir || d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		files, err := descriptorFiles(p)
+		if err != nil {
+			problems = append(problems, err)
+			return filepath.SkipDir
+		}
+		var plugins []string
+		for _, f := range files {
+			if filepath.Ext(f) == pluginExt {
+				plugins = append(plugins, f)
+			}
+		}
+		switch len(plugins) {
+		case 0:
+			return nil
+		case 1:
+		default:
+			problems = append(problems, ambiguityError(p, plugins))
+			return filepath.SkipDir
+		}
+
+		plugin, err := ReadProjectFile(plugins[0])
+		if err != nil {
+			problems = append(problems, err)
+			return filepath.SkipDir
+		}
+		if other := idx.Find(plugin.ProjectName); other != nil {
+			// the directories may overlap
+			if other.Path != plugin.Path() && !contains(other.Duplicates, plugin.Path()) {
+				other.Duplicates = append(other.Duplicates, plugin.Path())
+				problems = append(problems, errs.New(errs.Conflict, "plugin %s is found twice: %s and %s", plugin.ProjectName, other.Path, plugin.Path()))
+			}
+			return filepath.SkipDir
+		}
+		info := &PluginInfo{
+			Name:       plugin.ProjectName,
+			Path:       plugin.Path(),
+			Enabled:    pluginEnabled(project, plugin),
+			Descriptor: plugin,
+		}
+		for _, mdl := range plugin.Modules {
+			info.Modules = append(info.Modules, mdl.Name)
+		}
+		idx[info.Name] = info
+		// plugins are not nested into each other
+		return filepath.SkipDir
+	})
+	return problems
+}
+
+// DiscoverPlugins finds the plugins in the plugin directories of the project, including the nested folders.
+// The plugins, that can't be read, and the duplicated names don't fail the discovery, they are returned
+// as the problems with the index of the other plugins. The first of the duplicated plugins is in the index
+func DiscoverPlugins(project *ue.ProjectFileDescriptor) (PluginIndex, []error) {
+	idx := make(PluginIndex)
+	scanned := make(map[string]bool)
+	var problems []error
+	for _, dir := range project.PluginDirectories() {
+		if scanned[dir] {
+			continue
+		}
+		scanned[dir] = true
+		problems = append(problems, scanPlugins(project, dir, idx)...)
+	}
+	return idx, problems
+}
+
+// FindPlugin finds the plugin of the project by its name, the .uplugin may be nested in the plugin directories.
+// It fails, if the plugin is found several times
+func FindPlugin(project *ue.ProjectFileDescriptor, name string) (*ue.ProjectFileDescriptor, error) {
+	idx, problems := DiscoverPlugins(project)
+	info := idx.Find(name)
+	if info == nil {
+		err := errs.New(errs.ProjectNotFound, "plugin %s was not found in %s", name, strings.Join(project.PluginDirectories(), ", "))
+		if len(problems) > 0 {
+			err = errs.New(errs.ProjectNotFound, "%w\nskipped: %w", err, errors.Join(problems...))
+		}
+		return nil, err
+	}
+	if len(info.Duplicates) > 0 {
+		return nil, errs.New(errs.Conflict, "plugin %s is found several times: %s, %s", info.Name, info.Path, strings.Join(info.Duplicates, ", "))
+	}
+	return info.Descriptor, nil
+}
@@ -0,0 +1,147 @@
+package parse
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/config"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/printer"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/report"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/ue"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func newProjectID() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return strings.ToUpper(hex.EncodeToString(b)), nil
+}
+
+// writeNewFile writes the file, if it doesn't exist yet
+func writeNewFile(p string, print func(w io.Writer) error) error {
+	_, err := os.Stat(p)
+	if err == nil {
+		report.Warn("%s already exists, skipped", p)
+		return nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(p), 0755)
+	if err != nil {
+		return err
+	}
+	report.Touch(p)
+	f, err := os.Create(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return print(f)
+}
+
+// WriteProjectSkeleton writes project files, that are not related to the sources:
+// Config/DefaultEngine.ini, Config/DefaultGame.ini, .gitignore and Content folder.
+// Existing files are left untouched
+func WriteProjectSkeleton(projectFile *ue.ProjectFileDescriptor, cnf *config.AppConfig) error {
+	id, err := newProjectID()
+	if err != nil {
+		return err
+	}
+	ctx := printer.ProjectCtx{
+		Copyright:         cnf.Project.Copyright.Text,
+		ProjectName:       projectFile.ProjectName,
+		ProjectID:         id,
+		EngineAssociation: projectFile.EngineAssociation,
+	}
+
+	configDir := filepath.Join(projectFile.ProjectPath, "Config")
+	err = writeNewFile(filepath.Join(configDir, "DefaultEngine.ini"), func(w io.Writer) error {
+		return printer.PrintDefaultEngineIni(ctx, w)
+	})
+	if err != nil {
+		return err
+	}
+	err = writeNewFile(filepath.Join(configDir, "DefaultGame.ini"), func(w io.Writer) error {
+		return printer.PrintDefaultGameIni(ctx, w)
+	})
+	if err != nil {
+		return err
+	}
+	err = writeNewFile(filepath.Join(projectFile.ProjectPath, ".gitignore"), func(w io.Writer) error {
+		return printer.PrintGitignore(ctx, w)
+	})
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(filepath.Join(projectFile.ProjectPath, "Content"), 0755)
+}
+
+const DefaultIcon = "default"
+
+func copyFile(dst, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	return writeNewFile(dst, func(w io.Writer) error {
+		_, err := io.Copy(w, in)
+		return err
+	})
+}
+
+// WritePluginSkeleton writes the plugin folders and files requested in the config:
+// Content folder, Config/Default<Plugin>.ini, Config/FilterPlugin.ini and Resources/Icon128.png.
+// Existing files are left untouched
+func WritePluginSkeleton(pluginFile *ue.ProjectFileDescriptor, cnf *config.AppConfig) error {
+	ctx := printer.PluginCtx{
+		Copyright:   cnf.Project.Copyright.Text,
+		PluginName:  pluginFile.ProjectName,
+		HasConfig:   cnf.Project.DefaultConfig,
+		FilterPaths: cnf.Project.FilterPluginPaths,
+	}
+
+	var err error
+	if cnf.Project.Content {
+		err = os.MkdirAll(filepath.Join(pluginFile.ProjectPath, "Content"), 0755)
+		if err != nil {
+			return err
+		}
+	}
+
+	configDir := filepath.Join(pluginFile.ProjectPath, "Config")
+	if cnf.Project.DefaultConfig {
+		err = writeNewFile(filepath.Join(configDir, "Default"+pluginFile.ProjectName+".ini"), func(w io.Writer) error {
+			return printer.PrintDefaultPluginIni(ctx, w)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if cnf.Project.FilterPlugin || len(cnf.Project.FilterPluginPaths) > 0 {
+		err = writeNewFile(filepath.Join(configDir, "FilterPlugin.ini"), func(w io.Writer) error {
+			return printer.PrintFilterPluginIni(ctx, w)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	icon := filepath.Join(pluginFile.ProjectPath, "Resources", "Icon128.png")
+	switch cnf.Project.Icon {
+	case "":
+	case DefaultIcon:
+		err = writeNewFile(icon, printer.PrintDefaultIcon)
+	default:
+		err = copyFile(icon, cnf.ResolvePath(cnf.Project.Icon))
+	}
+	return err
+}
@@ -3,153 +3,93 @@ package parse
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
 	"github.com/sajoniks/ue-tools/module-tool/pkg/config"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/errs"
 	"github.com/sajoniks/ue-tools/module-tool/pkg/printer"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/report"
 	"github.com/sajoniks/ue-tools/module-tool/pkg/ue"
 	"io"
 	"io/fs"
 	"os"
-	"path"
 	"path/filepath"
+	"strings"
 )
 
 func readProjectDescriptor(reader io.Reader, plugin bool) (*ue.ProjectFileDescriptor, error) {
+	if plugin {
+		return readPluginDescriptor(reader)
+	}
 	desc := new(ue.ProjectFileDescriptor)
 	err := json.NewDecoder(reader).Decode(&desc)
 	if err != nil {
 		return nil, err
 	}
-	desc.IsPlugin = plugin
-	desc.Touch()
 	return desc, nil
 }
 
-func findProjectFile(dirPath string) (fs.File, error) {
-	files, err := os.ReadDir(dirPath)
+func readPluginDescriptor(reader io.Reader) (*ue.ProjectFileDescriptor, error) {
+	plugin := new(ue.PluginFileDescriptor)
+	err := json.NewDecoder(reader).Decode(plugin)
 	if err != nil {
 		return nil, err
 	}
-
-	var ff os.DirEntry
-	for _, file := range files {
-		info, err := file.Info()
-		if err != nil {
-			continue
-		}
-		if info.IsDir() || len(info.Name()) == 0 {
-			continue
-		}
-
-		ext := filepath.Ext(file.Name())
-		switch ext {
-		case ".uproject":
-			fallthrough
-		case ".uplugin":
-			ff = file
-		}
-		if ff != nil {
-			break
-		}
-	}
-
-	if ff == nil {
-		return nil, fmt.Errorf("project file was not found in the directory")
-	}
-
-	p := path.Join(dirPath, ff.Name())
-	return os.Open(p)
-}
-
-type iOperation interface {
-	Do() error
-	Undo()
-}
-
-type operationStack struct {
-	stack  []iOperation
-	failAt int
-}
-
-func (o *operationStack) run() error {
-	if o.failAt > -1 {
-		return errors.New("already failed")
-	}
-	for ix, op := range o.stack {
-		err := op.Do()
-		if err != nil {
-			o.failAt = ix
-			return err
-		}
-	}
-	o.failAt = -1
-	return nil
-}
-
-func (o *operationStack) rollback() {
-	for i := o.failAt; i >= 0; i-- {
-		o.stack[i].Undo()
+	desc := &ue.ProjectFileDescriptor{
+		IsPlugin:    true,
+		Plugin:      plugin,
+		FileVersion: plugin.FileVersion,
+		Category:    plugin.Category,
+		Description: plugin.Description,
+		Modules:     plugin.Modules,
+		Plugins:     plugin.Plugins,
 	}
-	o.failAt = -1
+	return desc, nil
 }
 
-func (o *operationStack) tryRun() error {
-	err := o.run()
+func findProjectFile(dirPath string) (fs.File, error) {
+	p, err := findDescriptor(dirPath)
 	if err != nil {
-		o.rollback()
-		return err
+		return nil, err
 	}
-	return nil
-}
-
-func newOperationStack(ops ...iOperation) operationStack {
-	return operationStack{
-		stack: ops,
+	if p == "" {
+		return nil, errs.New(errs.ProjectNotFound, "project file was not found in %s", dirPath)
 	}
+	return os.Open(p)
 }
 
-type writeProjectFileOperation struct {
-	openFileHandler bool
-	writtenFile     bool
-	projectFile     *ue.ProjectFileDescriptor
-}
-
-func (op *writeProjectFileOperation) Do() error {
-	f, err := os.Create(op.projectFile.Path())
+// WriteProjectDescriptor writes the .uproject or .uplugin file of the project.
+// The keys of the read file, that are not modelled, are written back as they were
+func WriteProjectDescriptor(projectFile *ue.ProjectFileDescriptor) error {
+	report.Touch(projectFile.Path())
+	f, err := os.Create(projectFile.Path())
 	if err != nil {
 		return err
 	}
 	defer f.Close()
-	op.openFileHandler = true
 	enc := json.NewEncoder(f)
 	enc.SetIndent("", "\t")
-	err = enc.Encode(&op.projectFile)
-	if err != nil {
-		return err
-	}
-	op.writtenFile = true
-	return nil
+	enc.SetEscapeHTML(false)
+	return enc.Encode(projectFile.Descriptor())
 }
 
 func createProjectDirectories(projectFile *ue.ProjectFileDescriptor) error {
 	_, err := os.Stat(projectFile.ProjectPath)
 	if err != nil && errors.Is(err, fs.ErrNotExist) {
-		err = os.MkdirAll(projectFile.ProjectPath, 0666)
+		err = os.MkdirAll(projectFile.ProjectPath, 0755)
 	}
 	return err
 }
 
 func WriteProjectModule(projectFile *ue.ProjectFileDescriptor, module *ue.ProjectModuleDescriptor, cnf *config.AppConfig) error {
 	var err error
-	err = os.MkdirAll(projectFile.ModuleSources(module.Name), 0666)
+	err = os.MkdirAll(projectFile.ModuleSources(module.Name), 0755)
 	if err != nil {
 		return err
 	}
-	err = os.MkdirAll(projectFile.ModulePublic(module.Name), 0666)
+	err = os.MkdirAll(projectFile.ModulePublic(module.Name), 0755)
 	if err != nil {
 		return err
 	}
-	err = os.MkdirAll(projectFile.ModulePrivate(module.Name), 0666)
+	err = os.MkdirAll(projectFile.ModulePrivate(module.Name), 0755)
 	if err != nil {
 		return err
 	}
@@ -159,7 +99,22 @@ func WriteProjectModule(projectFile *ue.ProjectFileDescriptor, module *ue.Projec
 		return err
 	}
 
-	err = writeModuleCppHeader(projectFile, module.Name, cnf)
+	err = writeModuleCppHeader(projectFile, module, cnf)
+	if err != nil {
+		return err
+	}
+
+	err = writeModuleApiHeader(projectFile, module, cnf)
+	if err != nil {
+		return err
+	}
+
+	err = writeModuleCpp(projectFile, module, cnf)
+	if err != nil {
+		return err
+	}
+
+	err = writeModuleFiles(projectFile, module, cnf)
 	if err != nil {
 		return err
 	}
@@ -176,22 +131,87 @@ func writeProjectModules(projectFile *ue.ProjectFileDescriptor, cnf *config.AppC
 	return nil
 }
 
+// BuildCondition makes C# condition of the ModuleRules from the conditional dependencies, e.g. "Target.bBuildEditor"
+func BuildCondition(cond *config.ConditionalDependencies) string {
+	var groups [][]string
+	if cond.Editor != nil {
+		if *cond.Editor {
+			groups = append(groups, []string{"Target.bBuildEditor"})
+		} else {
+			groups = append(groups, []string{"!Target.bBuildEditor"})
+		}
+	}
+	if len(cond.Platforms) > 0 {
+		var alts []string
+		for _, p := range cond.Platforms {
+			alts = append(alts, "Target.Platform == UnrealTargetPlatform."+p)
+		}
+		groups = append(groups, alts)
+	}
+	if len(cond.Targets) > 0 {
+		var alts []string
+		for _, t := range cond.Targets {
+			alts = append(alts, "Target.Type == TargetType."+t.String())
+		}
+		groups = append(groups, alts)
+	}
+
+	if len(groups) == 0 {
+		return "true"
+	}
+	parts := make([]string, 0, len(groups))
+	for _, alts := range groups {
+		if len(alts) > 1 && len(groups) > 1 {
+			parts = append(parts, "("+strings.Join(alts, " || ")+")")
+		} else {
+			parts = append(parts, strings.Join(alts, " || "))
+		}
+	}
+	return strings.Join(parts, " && ")
+}
+
 func writeModuleBuildCs(projectFile *ue.ProjectFileDescriptor, moduleName string, cnf *config.AppConfig) error {
 	var ctx printer.BuildFileCtx
 	for i, _ := range cnf.Modules {
 		if cnf.Modules[i].Name != moduleName {
 			continue
 		}
+		var public []string
+		public = append(public, cnf.Modules[i].Dependencies.Public...)
+		public = append(public, cnf.Modules[i].FileRequirements()...)
+		mc := &cnf.Modules[i]
 		ctx = printer.BuildFileCtx{
 			Copyright:           cnf.Project.Copyright.Text,
 			ModuleName:          moduleName,
-			PublicDependencies:  cnf.Modules[i].Dependencies.Public,
-			PrivateDependencies: cnf.Modules[i].Dependencies.Private,
+			PublicDependencies:  public,
+			PrivateDependencies: mc.Dependencies.Private,
+
+			DynamicallyLoaded:        mc.Dependencies.DynamicallyLoaded,
+			PublicIncludePathModules: mc.Dependencies.PublicIncludePathModules,
+			PrivateIncludePaths:      mc.Build.PrivateIncludePaths,
+			PublicDefinitions:        mc.Build.PublicDefinitions,
+			PCHUsage:                 mc.Build.PCHUsage,
+			PrivatePCHHeaderFile:     mc.Build.PrivatePCHHeaderFile,
+			CppStandard:              mc.Build.CppStandard,
+			EnforceIWYU:              mc.Build.EnforceIWYU,
+			IWYUSupport:              mc.Build.IWYUSupport,
+			UseUnity:                 mc.Build.UseUnity,
+			OptimizeCode:             mc.Build.OptimizeCode,
+		}
+		for _, cond := range mc.Dependencies.Conditional {
+			ctx.Conditions = append(ctx.Conditions, printer.BuildConditionCtx{
+				Condition:           BuildCondition(&cond),
+				PublicDependencies:  cond.Public,
+				PrivateDependencies: cond.Private,
+				DynamicallyLoaded:   cond.DynamicallyLoaded,
+				PublicDefinitions:   cond.PublicDefinitions,
+			})
 		}
 		break
 	}
 	if ctx.ModuleName != "" {
 		p := filepath.Join(projectFile.ModuleSources(moduleName), moduleName+".Build.cs")
+		report.Touch(p)
 		f, err := os.Create(p)
 		if err != nil {
 			return err
@@ -203,20 +223,27 @@ func writeModuleBuildCs(projectFile *ue.ProjectFileDescriptor, moduleName string
 	}
 }
 
-func writeModuleCppHeader(projectFile *ue.ProjectFileDescriptor, moduleName string, cnf *config.AppConfig) error {
+func isGameModule(projectFile *ue.ProjectFileDescriptor, module *ue.ProjectModuleDescriptor) bool {
+	return !projectFile.IsPlugin && module.Type == ue.ModuleRuntime
+}
+
+func writeModuleCppHeader(projectFile *ue.ProjectFileDescriptor, module *ue.ProjectModuleDescriptor, cnf *config.AppConfig) error {
+	moduleName := module.Name
 	var ctx printer.ModuleCppHeaderCtx
 	for i, _ := range cnf.Modules {
 		if cnf.Modules[i].Name != moduleName {
 			continue
 		}
 		ctx = printer.ModuleCppHeaderCtx{
-			Copyright:  cnf.Project.Copyright.Text,
-			ModuleName: moduleName,
+			Copyright:    cnf.Project.Copyright.Text,
+			ModuleName:   moduleName,
+			IsGameModule: isGameModule(projectFile, module),
 		}
 		break
 	}
 	if ctx.ModuleName != "" {
 		p := filepath.Join(projectFile.ModuleSources(moduleName), "Public", moduleName+".h")
+		report.Touch(p)
 		f, err := os.Create(p)
 		if err != nil {
 			return err
@@ -228,6 +255,57 @@ func writeModuleCppHeader(projectFile *ue.ProjectFileDescriptor, moduleName stri
 	}
 }
 
+func writeModuleApiHeader(projectFile *ue.ProjectFileDescriptor, module *ue.ProjectModuleDescriptor, cnf *config.AppConfig) error {
+	mc := cnf.Module(module.Name)
+	if mc == nil {
+		return errors.New("module not found")
+	}
+	name := mc.ApiHeader.FileName(module.Name)
+	if name == "" {
+		return nil
+	}
+	ctx := printer.ApiHeaderCtx{
+		Copyright:    cnf.Project.Copyright.Text,
+		ModuleName:   module.Name,
+		ModuleApi:    ue.ApiMacro(module.Name),
+		LogCategory:  mc.ApiHeader.LogCategory,
+		LogVerbosity: mc.ApiHeader.LogVerbosity,
+		StatsGroup:   mc.ApiHeader.StatsGroup,
+	}
+	p := filepath.Join(projectFile.ModulePublic(module.Name), name)
+	report.Touch(p)
+	f, err := os.Create(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return printer.PrintApiHeader(ctx, f)
+}
+
+func writeModuleCpp(projectFile *ue.ProjectFileDescriptor, module *ue.ProjectModuleDescriptor, cnf *config.AppConfig) error {
+	mc := cnf.Module(module.Name)
+	if mc == nil {
+		return errors.New("module not found")
+	}
+	ctx := printer.ModuleCppCtx{
+		Copyright:           cnf.Project.Copyright.Text,
+		ProjectName:         projectFile.ProjectName,
+		ModuleName:          module.Name,
+		IsGameModule:        isGameModule(projectFile, module),
+		IsPrimaryGameModule: isGameModule(projectFile, module) && module.Name == projectFile.ProjectName,
+		ApiHeader:           mc.ApiHeader.FileName(module.Name),
+		LogCategory:         mc.ApiHeader.LogCategory,
+	}
+	p := filepath.Join(projectFile.ModulePrivate(module.Name), module.Name+".cpp")
+	report.Touch(p)
+	f, err := os.Create(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return printer.PrintModuleCpp(ctx, f)
+}
+
 func WriteProjectFile(projectFile *ue.ProjectFileDescriptor, cnf *config.AppConfig) error {
 	err := createProjectDirectories(projectFile)
 	if err != nil {
@@ -238,7 +316,7 @@ func WriteProjectFile(projectFile *ue.ProjectFileDescriptor, cnf *config.AppConf
 	if err != nil {
 		return err
 	}
-	return nil
+	return WriteProjectDescriptor(projectFile)
 }
 
 func readFolderNames(dir string) ([]string, error) {
@@ -258,9 +336,11 @@ func readFolderNames(dir string) ([]string, error) {
 	return folders, nil
 }
 
+// ReadPluginsList returns the names of the project plugins, see DiscoverPlugins.
+// The plugins, that can't be read, are not listed
 func ReadPluginsList(projectFile *ue.ProjectFileDescriptor) ([]string, error) {
-	pluginsDir := filepath.Join(projectFile.ProjectPath, "Plugins")
-	return readFolderNames(pluginsDir)
+	idx, _ := DiscoverPlugins(projectFile)
+	return idx.Names(), nil
 }
 
 func ReadModulesList(projectFile *ue.ProjectFileDescriptor) ([]string, error) {
@@ -268,8 +348,20 @@ func ReadModulesList(projectFile *ue.ProjectFileDescriptor) ([]string, error) {
 	return readFolderNames(modulesDir)
 }
 
+// ReadProjectFile reads the .uproject or .uplugin file, or the only one of them in the directory.
+// If the path is empty, the nearest descriptor is found from the working directory
 func ReadProjectFile(dirPath string) (*ue.ProjectFileDescriptor, error) {
+	if dirPath == "" {
+		loc, err := DiscoverProject(".")
+		if err != nil {
+			return nil, err
+		}
+		return loc.Target(), nil
+	}
 	stat, err := os.Stat(dirPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, errs.New(errs.ProjectNotFound, "project %q was not found", dirPath)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -286,17 +378,20 @@ func ReadProjectFile(dirPath string) (*ue.ProjectFileDescriptor, error) {
 	}
 	defer projectFile.Close()
 
-	stat, _ = projectFile.Stat()
 	projPath, _ := filepath.Abs(dirPath)
+	if !stat.IsDir() {
+		projPath = filepath.Dir(projPath)
+	}
+	stat, _ = projectFile.Stat()
 
 	var name string
 	var isPlugin bool
 
 	ext := filepath.Ext(stat.Name())
 	switch ext {
-	case ".uplugin":
+	case pluginExt:
 		isPlugin = true
-	case ".uproject":
+	case projectExt:
 		isPlugin = false
 	}
 	name = stat.Name()
@@ -304,27 +399,11 @@ func ReadProjectFile(dirPath string) (*ue.ProjectFileDescriptor, error) {
 
 	desc, err := readProjectDescriptor(projectFile, isPlugin)
 	if err != nil {
-		return nil, err
+		return nil, errs.New(errs.Validation, "%s: %w", stat.Name(), err)
 	}
 	desc.ProjectPath = projPath
 	desc.ProjectFileName = stat.Name()
 	desc.ProjectName = name
 
-	// fix up plugins (in the list, but not in the filesystem)
-	plugins, err := ReadPluginsList(desc)
-	if err != nil {
-		return nil, err
-	}
-	for _, pl := range plugins {
-		for ix, ixPl := range desc.Plugins {
-			if ixPl.Name != pl {
-				continue
-			}
-
-			desc.Plugins = append(desc.Plugins[:ix], desc.Plugins[:ix+1]...)
-			break
-		}
-	}
-
 	return desc, nil
 }
@@ -0,0 +1,445 @@
+// Package plan compares the project with the config and lists the changes,
+// that make the project match the config: modules, their dependencies and plugin references
+package plan
+
+import (
+	"fmt"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/config"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/cs"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/errs"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/parse"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/ue"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type Action int
+
+const (
+	Add Action = iota
+	Update
+	Remove
+)
+
+func (a Action) String() string {
+	switch a {
+	case Add:
+		return "add"
+	case Update:
+		return "update"
+	case Remove:
+		return "remove"
+	}
+	return ""
+}
+
+func (a Action) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// Symbol is the mark of the action in the printed plan
+func (a Action) Symbol() string {
+	switch a {
+	case Add:
+		return "+"
+	case Update:
+		return "~"
+	case Remove:
+		return "-"
+	}
+	return "?"
+}
+
+// Resources of the changes
+const (
+	ResourceModule         = "module"
+	ResourceExternalModule = "external module"
+	ResourceDependency     = "dependency"
+	ResourcePlugin         = "plugin"
+)
+
+// Attribute is the changed value of the updated resource, or the value of the added one
+type Attribute struct {
+	Name string `json:"name"`
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+type Change struct {
+	Action   Action `json:"action"`
+	Resource string `json:"resource"`
+	// Name is the name of the module, the plugin, or the dependency
+	Name string `json:"name"`
+	// Module and Property are the Build.cs array of the dependency, e.g. PublicDependencyModuleNames
+	Module   string `json:"module,omitempty"`
+	Property string `json:"property,omitempty"`
+	// Condition is the condition of the if block of the Build.cs with the dependency, empty for the rules constructor itself
+	Condition  string      `json:"condition,omitempty"`
+	Attributes []Attribute `json:"attributes,omitempty"`
+	// File is the file, that is changed: the descriptor, the Build.cs, or the folder of the external module
+	File string `json:"file"`
+}
+
+// Title describes the changed resource, e.g. "dependency UMG of Foo.Build.cs PublicDependencyModuleNames"
+// or "dependency UnrealEd of Foo.Build.cs PublicDependencyModuleNames if (Target.bBuildEditor)"
+func (c *Change) Title() string {
+	if c.Resource == ResourceDependency && c.Condition != "" {
+		return fmt.Sprintf("%s %s of %s.Build.cs %s if (%s)", c.Resource, c.Name, c.Module, c.Property, c.Condition)
+	}
+	if c.Resource == ResourceDependency {
+		return fmt.Sprintf("%s %s of %s.Build.cs %s", c.Resource, c.Name, c.Module, c.Property)
+	}
+	return c.Resource + " " + c.Name
+}
+
+// Divergence describes the change as the difference of the project from the config, e.g. "missing module Foo"
+func (c *Change) Divergence() string {
+	switch c.Action {
+	case Add:
+		return "missing " + c.Title()
+	case Remove:
+		return "extra " + c.Title()
+	}
+	if from, to, ok := c.ConditionChange(); ok {
+		return fmt.Sprintf("wrong condition of %s: it is in %s, the config has %s", c.Title(), blockName(from), blockName(to))
+	}
+	diffs := make([]string, len(c.Attributes))
+	for i, attr := range c.Attributes {
+		diffs[i] = fmt.Sprintf("%s is %q, the config has %q", attr.Name, attr.From, attr.To)
+	}
+	return fmt.Sprintf("%s: %s", c.Title(), strings.Join(diffs, ", "))
+}
+
+// ConditionChange returns the conditions of the Build.cs blocks, the updated dependency is moved between
+func (c *Change) ConditionChange() (from, to string, ok bool) {
+	if c.Resource != ResourceDependency || c.Action != Update {
+		return "", "", false
+	}
+	for _, attr := range c.Attributes {
+		if attr.Name == "condition" {
+			return attr.From, attr.To, true
+		}
+	}
+	return "", "", false
+}
+
+// blockName describes the block of the Build.cs by its condition
+func blockName(cond string) string {
+	if cond == "" {
+		return "the rules constructor"
+	}
+	return fmt.Sprintf("if (%s)", cond)
+}
+
+type Plan struct {
+	Changes []*Change `json:"changes"`
+}
+
+func (p *Plan) add(c *Change) {
+	p.Changes = append(p.Changes, c)
+}
+
+// Empty checks, if the project already matches the config
+func (p *Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// Count returns the number of the changes with the action
+func (p *Plan) Count(action Action) int {
+	n := 0
+	for _, c := range p.Changes {
+		if c.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// Print prints the plan in the form of terraform plan
+func (p *Plan) Print(w io.Writer) {
+	if p.Empty() {
+		fmt.Fprintln(w, "No changes. The project matches the config.")
+		return
+	}
+	for _, c := range p.Changes {
+		fmt.Fprintf(w, "  %s %s\n", c.Action.Symbol(), c.Title())
+		width := 0
+		for _, attr := range c.Attributes {
+			width = max(width, len(attr.Name)+1)
+		}
+		for _, attr := range c.Attributes {
+			if c.Action == Update {
+				fmt.Fprintf(w, "      %-*s %q -> %q\n", width, attr.Name+":", attr.From, attr.To)
+			} else {
+				fmt.Fprintf(w, "      %-*s %q\n", width, attr.Name+":", attr.To)
+			}
+		}
+	}
+	fmt.Fprintf(w, "\nPlan: %d to add, %d to change, %d to remove.\n", p.Count(Add), p.Count(Update), p.Count(Remove))
+}
+
+// DriftError is the error of the check, listing the divergences of the project from the config
+type DriftError struct {
+	Plan *Plan
+}
+
+func (e *DriftError) Error() string {
+	lines := make([]string, len(e.Plan.Changes))
+	for i, c := range e.Plan.Changes {
+		lines[i] = c.Divergence()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Diff compares the project, or the plugin, with the config
+func Diff(projectFile *ue.ProjectFileDescriptor, cnf *config.AppConfig) (*Plan, error) {
+	p := &Plan{Changes: []*Change{}}
+	diffModules(p, projectFile, cnf)
+	err := diffDependencies(p, projectFile, cnf)
+	if err != nil {
+		return nil, err
+	}
+	diffPlugins(p, projectFile, cnf)
+	return p, nil
+}
+
+func findModule(projectFile *ue.ProjectFileDescriptor, name string) *ue.ProjectModuleDescriptor {
+	for _, mdl := range projectFile.Modules {
+		if mdl.Name == name {
+			return mdl
+		}
+	}
+	return nil
+}
+
+func diffModules(p *Plan, projectFile *ue.ProjectFileDescriptor, cnf *config.AppConfig) {
+	for i := range cnf.Modules {
+		mc := &cnf.Modules[i]
+		if mc.IsExternal() {
+			// external modules are not in the descriptor, only their sources are checked
+			if _, err := os.Stat(projectFile.ThirdPartySources(mc.Name)); err != nil {
+				p.add(&Change{Action: Add, Resource: ResourceExternalModule, Name: mc.Name, File: projectFile.ThirdPartySources(mc.Name)})
+			}
+			continue
+		}
+		mdl := findModule(projectFile, mc.Name)
+		if mdl == nil {
+			p.add(&Change{
+				Action:   Add,
+				Resource: ResourceModule,
+				Name:     mc.Name,
+				File:     projectFile.Path(),
+				Attributes: []Attribute{
+					{Name: "type", To: mc.Type.String()},
+					{Name: "loading_phase", To: mc.LoadingPhase.String()},
+				},
+			})
+			continue
+		}
+		var attrs []Attribute
+		if mdl.Type != mc.Type {
+			attrs = append(attrs, Attribute{Name: "type", From: mdl.TypeName(), To: mc.Type.String()})
+		}
+		if mdl.LoadingPhase != mc.LoadingPhase {
+			attrs = append(attrs, Attribute{Name: "loading_phase", From: mdl.LoadingPhaseName(), To: mc.LoadingPhase.String()})
+		}
+		if len(attrs) > 0 {
+			p.add(&Change{Action: Update, Resource: ResourceModule, Name: mc.Name, Attributes: attrs, File: projectFile.Path()})
+		}
+	}
+	for _, mdl := range projectFile.Modules {
+		if cnf.Module(mdl.Name) == nil {
+			p.add(&Change{Action: Remove, Resource: ResourceModule, Name: mdl.Name, File: projectFile.Path()})
+		}
+	}
+}
+
+func appendUnique(ls []string, values ...string) []string {
+	for _, v := range values {
+		if !contains(ls, v) {
+			ls = append(ls, v)
+		}
+	}
+	return ls
+}
+
+func contains(ls []string, s string) bool {
+	for _, v := range ls {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// scope is the dependency arrays of one block of the Build.cs: the rules constructor, or the if block with the condition
+type scope struct {
+	cond string
+	deps map[string][]string
+}
+
+func findScope(scopes []*scope, cond string) *scope {
+	for _, sc := range scopes {
+		if cs.SameCondition(sc.cond, cond) {
+			return sc
+		}
+	}
+	return nil
+}
+
+// desiredDependencies returns the entries of the Build.cs arrays, the config renders into, by the blocks:
+// the rules constructor first, then the if blocks of the conditional dependencies
+func desiredDependencies(mc *config.ModuleConfig) []*scope {
+	public := appendUnique(nil, mc.Dependencies.Public...)
+	public = appendUnique(public, mc.FileRequirements()...)
+	scopes := []*scope{{deps: map[string][]string{
+		parse.PublicDependencies:  public,
+		parse.PrivateDependencies: appendUnique(nil, mc.Dependencies.Private...),
+	}}}
+	for i := range mc.Dependencies.Conditional {
+		cond := &mc.Dependencies.Conditional[i]
+		sc := findScope(scopes, parse.BuildCondition(cond))
+		if sc == nil {
+			sc = &scope{cond: parse.BuildCondition(cond), deps: make(map[string][]string)}
+			scopes = append(scopes, sc)
+		}
+		sc.deps[parse.PublicDependencies] = appendUnique(sc.deps[parse.PublicDependencies], cond.Public...)
+		sc.deps[parse.PrivateDependencies] = appendUnique(sc.deps[parse.PrivateDependencies], cond.Private...)
+	}
+	return scopes
+}
+
+// diffDependencies compares the dependency arrays of the Build.cs of the modules, that are both in the project and in the config.
+// The rules constructor and every if block are compared separately, the if blocks, the config doesn't have, must have no dependencies
+func diffDependencies(p *Plan, projectFile *ue.ProjectFileDescriptor, cnf *config.AppConfig) error {
+	for i := range cnf.Modules {
+		mc := &cnf.Modules[i]
+		if mc.IsExternal() || findModule(projectFile, mc.Name) == nil {
+			continue
+		}
+		src, err := parse.ReadBuildCs(projectFile, mc.Name)
+		if err != nil {
+			return errs.New(errs.IO, "module %s: %w", mc.Name, err)
+		}
+		file := parse.BuildCsPath(projectFile, mc.Name)
+		var changes []*Change
+		scopes := desiredDependencies(mc)
+		for _, cond := range src.Conditions() {
+			if findScope(scopes, cond) == nil {
+				scopes = append(scopes, &scope{cond: cond, deps: make(map[string][]string)})
+			}
+		}
+		for _, sc := range scopes {
+			for _, property := range []string{parse.PublicDependencies, parse.PrivateDependencies} {
+				desired := sc.deps[property]
+				actual := appendUnique(nil, src.EntriesIf(sc.cond, property)...)
+				for _, dep := range desired {
+					if !contains(actual, dep) {
+						changes = append(changes, &Change{Action: Add, Resource: ResourceDependency, Name: dep, Module: mc.Name, Property: property, Condition: sc.cond, File: file})
+					}
+				}
+				for _, dep := range actual {
+					if !contains(desired, dep) {
+						changes = append(changes, &Change{Action: Remove, Resource: ResourceDependency, Name: dep, Module: mc.Name, Property: property, Condition: sc.cond, File: file})
+					}
+				}
+			}
+		}
+		for _, c := range moveDependencies(changes) {
+			p.add(c)
+		}
+	}
+	return nil
+}
+
+// moveDependencies replaces the removal of the dependency from one block of the Build.cs and its addition
+// to another block with the update of its condition
+func moveDependencies(changes []*Change) []*Change {
+	pairs := make(map[*Change]*Change)
+	moved := make(map[*Change]bool)
+	for _, add := range changes {
+		if add.Action != Add {
+			continue
+		}
+		for _, rm := range changes {
+			if rm.Action == Remove && !moved[rm] && rm.Name == add.Name && rm.Property == add.Property {
+				pairs[add] = rm
+				moved[rm] = true
+				break
+			}
+		}
+	}
+	var res []*Change
+	for _, c := range changes {
+		if moved[c] {
+			continue
+		}
+		rm, ok := pairs[c]
+		if !ok {
+			res = append(res, c)
+			continue
+		}
+		res = append(res, &Change{
+			Action:     Update,
+			Resource:   ResourceDependency,
+			Name:       c.Name,
+			Module:     c.Module,
+			Property:   c.Property,
+			Attributes: []Attribute{{Name: "condition", From: rm.Condition, To: c.Condition}},
+			File:       c.File,
+		})
+	}
+	return res
+}
+
+// diffPlugins compares the plugin references of the descriptor, if the config sets them
+func diffPlugins(p *Plan, projectFile *ue.ProjectFileDescriptor, cnf *config.AppConfig) {
+	if !cnf.ManagesPlugins() {
+		return
+	}
+	find := func(name string) *ue.PluginDescriptor {
+		for _, pl := range projectFile.Plugins {
+			if pl.Name == name {
+				return pl
+			}
+		}
+		return nil
+	}
+	for _, want := range cnf.Project.Plugins {
+		pl := find(want.Name)
+		if pl == nil {
+			p.add(&Change{
+				Action:   Add,
+				Resource: ResourcePlugin,
+				Name:     want.Name,
+				File:     projectFile.Path(),
+				Attributes: []Attribute{
+					{Name: "enabled", To: strconv.FormatBool(want.Enabled)},
+					{Name: "optional", To: strconv.FormatBool(want.Optional)},
+				},
+			})
+			continue
+		}
+		var attrs []Attribute
+		if pl.Enabled != want.Enabled {
+			attrs = append(attrs, Attribute{Name: "enabled", From: strconv.FormatBool(pl.Enabled), To: strconv.FormatBool(want.Enabled)})
+		}
+		if pl.Optional != want.Optional {
+			attrs = append(attrs, Attribute{Name: "optional", From: strconv.FormatBool(pl.Optional), To: strconv.FormatBool(want.Optional)})
+		}
+		if len(attrs) > 0 {
+			p.add(&Change{Action: Update, Resource: ResourcePlugin, Name: want.Name, Attributes: attrs, File: projectFile.Path()})
+		}
+	}
+	for _, pl := range projectFile.Plugins {
+		var wanted bool
+		for _, want := range cnf.Project.Plugins {
+			wanted = wanted || want.Name == pl.Name
+		}
+		if !wanted {
+			p.add(&Change{Action: Remove, Resource: ResourcePlugin, Name: pl.Name, File: projectFile.Path()})
+		}
+	}
+}
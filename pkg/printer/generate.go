@@ -1,7 +1,10 @@
 package printer
 
 import (
+	"github.com/sajoniks/ue-tools/module-tool/pkg/errs"
 	"io"
+	"strings"
+	"text/template"
 )
 
 type ModuleCppHeaderCtx struct {
@@ -15,6 +18,127 @@ type BuildFileCtx struct {
 	ModuleName          string
 	PublicDependencies  []string
 	PrivateDependencies []string
+
+	DynamicallyLoaded        []string
+	PublicIncludePathModules []string
+	PrivateIncludePaths      []string
+	PublicDefinitions        []string
+	PCHUsage                 string
+	PrivatePCHHeaderFile     string
+	CppStandard              string
+	EnforceIWYU              *bool
+	IWYUSupport              string
+	UseUnity                 *bool
+	OptimizeCode             string
+	Conditions               []BuildConditionCtx
+}
+
+type ExternalBuildFileCtx struct {
+	Copyright   string
+	ModuleName  string
+	IncludeDirs []string
+	Definitions []string
+	Platforms   []ExternalPlatformCtx
+}
+
+type ExternalPlatformCtx struct {
+	Name                string
+	Libraries           []string
+	DelayLoadDLLs       []string
+	RuntimeDependencies []string
+}
+
+// BuildConditionCtx is a block of dependencies added under the condition, e.g. "Target.bBuildEditor"
+type BuildConditionCtx struct {
+	Condition           string
+	PublicDependencies  []string
+	PrivateDependencies []string
+	DynamicallyLoaded   []string
+	PublicDefinitions   []string
+}
+
+type TargetFileCtx struct {
+	Copyright           string
+	TargetName          string
+	Type                string
+	BuildSettings       string
+	IncludeOrderVersion string
+	ExtraModules        []string
+}
+
+type ModuleCppCtx struct {
+	Copyright           string
+	ProjectName         string
+	ModuleName          string
+	IsGameModule        bool
+	IsPrimaryGameModule bool
+	ApiHeader           string
+	LogCategory         bool
+}
+
+type ApiHeaderCtx struct {
+	Copyright    string
+	ModuleName   string
+	ModuleApi    string
+	LogCategory  bool
+	LogVerbosity string
+	StatsGroup   bool
+}
+
+type ProjectCtx struct {
+	Copyright         string
+	ProjectName       string
+	ProjectID         string
+	EngineAssociation string
+}
+
+type ClassCtx struct {
+	Copyright     string
+	ModuleApi     string
+	ClassName     string
+	FullName      string
+	Parent        string
+	ParentInclude string
+	HeaderInclude string
+	Preset        string
+	Specifiers    []string
+}
+
+// UserFileCtx is passed to the templates of custom module files and to their paths
+type UserFileCtx struct {
+	Copyright   string
+	ProjectName string
+	ModuleName  string
+	ModuleApi   string
+	Vars        map[string]string
+}
+
+type PluginCtx struct {
+	Copyright   string
+	PluginName  string
+	HasConfig   bool
+	FilterPaths []string
+}
+
+// ConfigCtx is the config file written by the init wizard
+type ConfigCtx struct {
+	Name              string
+	IsPlugin          bool
+	EngineAssociation string
+	Copyright         []string
+	Category          string
+	Description       string
+	Modules           []ConfigModuleCtx
+	ModuleTypes       []string
+	LoadingPhases     []string
+}
+
+type ConfigModuleCtx struct {
+	Name         string
+	Type         string
+	LoadingPhase string
+	Public       []string
+	Private      []string
 }
 
 func PrintModuleCppHeader(ctx ModuleCppHeaderCtx, w io.Writer) error {
@@ -40,3 +164,96 @@ func PrintModuleBuildCs(ctx BuildFileCtx, w io.Writer) error {
 	}
 	return nil
 }
+
+func PrintModuleCpp(ctx ModuleCppCtx, w io.Writer) error {
+	tpl := moduleCppTemplate()
+	if ctx.Copyright == "" {
+		ctx.Copyright = defaultCopyright
+	}
+	return tpl.Execute(w, &ctx)
+}
+
+func PrintTargetCs(ctx TargetFileCtx, w io.Writer) error {
+	tpl := targetFile()
+	if ctx.Copyright == "" {
+		ctx.Copyright = defaultCopyright
+	}
+	return tpl.Execute(w, &ctx)
+}
+
+func PrintDefaultEngineIni(ctx ProjectCtx, w io.Writer) error {
+	return globalTpl.ExecuteTemplate(w, "default_engine_ini", &ctx)
+}
+
+func PrintDefaultGameIni(ctx ProjectCtx, w io.Writer) error {
+	return globalTpl.ExecuteTemplate(w, "default_game_ini", &ctx)
+}
+
+func PrintGitignore(ctx ProjectCtx, w io.Writer) error {
+	return globalTpl.ExecuteTemplate(w, "gitignore", &ctx)
+}
+
+func PrintUserFile(name string, ctx UserFileCtx, w io.Writer) error {
+	tpl := lookupTemplate(name)
+	if tpl == nil {
+		return errs.New(errs.Config, "template %q is not defined", name)
+	}
+	if ctx.Copyright == "" {
+		ctx.Copyright = defaultCopyright
+	}
+	return tpl.Execute(w, &ctx)
+}
+
+// ExpandPath executes the path template of the custom file
+func ExpandPath(path string, ctx UserFileCtx) (string, error) {
+	tpl, err := template.New("path").Funcs(tplFuncs).Parse(path)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	err = tpl.Execute(&sb, &ctx)
+	if err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+func PrintClassHeader(ctx ClassCtx, w io.Writer) error {
+	if ctx.Copyright == "" {
+		ctx.Copyright = defaultCopyright
+	}
+	return globalTpl.ExecuteTemplate(w, "class_header", &ctx)
+}
+
+func PrintClassCpp(ctx ClassCtx, w io.Writer) error {
+	if ctx.Copyright == "" {
+		ctx.Copyright = defaultCopyright
+	}
+	return globalTpl.ExecuteTemplate(w, "class_cpp", &ctx)
+}
+
+func PrintExternalBuildCs(ctx ExternalBuildFileCtx, w io.Writer) error {
+	if ctx.Copyright == "" {
+		ctx.Copyright = defaultCopyright
+	}
+	return globalTpl.ExecuteTemplate(w, "external_build_file", &ctx)
+}
+
+func PrintApiHeader(ctx ApiHeaderCtx, w io.Writer) error {
+	if ctx.Copyright == "" {
+		ctx.Copyright = defaultCopyright
+	}
+	return globalTpl.ExecuteTemplate(w, "api_header", &ctx)
+}
+
+func PrintDefaultPluginIni(ctx PluginCtx, w io.Writer) error {
+	return globalTpl.ExecuteTemplate(w, "default_plugin_ini", &ctx)
+}
+
+func PrintFilterPluginIni(ctx PluginCtx, w io.Writer) error {
+	return globalTpl.ExecuteTemplate(w, "filter_plugin_ini", &ctx)
+}
+
+func PrintConfig(ctx ConfigCtx, w io.Writer) error {
+	return globalTpl.ExecuteTemplate(w, "config_yaml", &ctx)
+}
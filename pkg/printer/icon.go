@@ -0,0 +1,30 @@
+package printer
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+const iconSize = 128
+
+// PrintDefaultIcon writes the default 128x128 plugin icon (Resources/Icon128.png)
+func PrintDefaultIcon(w io.Writer) error {
+	img := image.NewNRGBA(image.Rect(0, 0, iconSize, iconSize))
+	for y := 0; y < iconSize; y++ {
+		for x := 0; x < iconSize; x++ {
+			c := color.NRGBA{R: 32, G: uint8(48 + y/4), B: uint8(96 + x/2), A: 255}
+			// light frame and a plug-like square in the center
+			if x < 6 || y < 6 || x >= iconSize-6 || y >= iconSize-6 {
+				c = color.NRGBA{R: 200, G: 210, B: 225, A: 255}
+			} else if x >= 40 && x < 88 && y >= 40 && y < 88 {
+				c = color.NRGBA{R: 235, G: 240, B: 245, A: 255}
+			} else if (x >= 52 && x < 60 || x >= 68 && x < 76) && y >= 20 && y < 40 {
+				c = color.NRGBA{R: 235, G: 240, B: 245, A: 255}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return png.Encode(w, img)
+}
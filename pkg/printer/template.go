@@ -2,6 +2,11 @@ package printer
 
 import (
 	"fmt"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/errs"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/report"
+	"os"
+	"path"
+	"path/filepath"
 	"strings"
 	"text/template"
 )
@@ -20,6 +25,23 @@ var tplFuncs = template.FuncMap{
 		}
 		return cp
 	},
+	"join": func(sep string, ls []string) string {
+		return strings.Join(ls, sep)
+	},
+	"base": path.Base,
+	"args": func(args ...any) []any {
+		return args
+	},
+	// include executes the template by the name, if it's defined
+	"include": func(name string, data any) (string, error) {
+		tpl := globalTpl.Lookup(name)
+		if tpl == nil {
+			return "", nil
+		}
+		var sb strings.Builder
+		err := tpl.Execute(&sb, data)
+		return sb.String(), err
+	},
 }
 
 var globalTpl *template.Template
@@ -50,21 +72,144 @@ func loadTemplates(fs ...templateString) (*template.Template, error) {
 	return tpl, nil
 }
 
+var builtinTemplates = []templateString{
+	fromString("copyright", templateCopyright),
+	fromString("header", templateHeader),
+	fromString("add_range", templateAddRange),
+	fromString("build_file", templateBuildCs),
+	fromString("external_build_file", templateExternalBuildCs),
+	fromString("module_cpp", templateModuleCpp),
+	fromString("api_header", templateApiHeader),
+	fromString("target_file", templateTargetCs),
+	fromString("default_engine_ini", templateDefaultEngineIni),
+	fromString("default_game_ini", templateDefaultGameIni),
+	fromString("gitignore", templateGitignore),
+	fromString("default_plugin_ini", templateDefaultPluginIni),
+	fromString("filter_plugin_ini", templateFilterPluginIni),
+	fromString("config_yaml", templateConfigYaml),
+	fromString("class_header", templateClassHeader),
+	fromString("class_cpp", templateClassCpp),
+	fromString("class_h_Actor", templateClassActorH),
+	fromString("class_cpp_Actor", templateClassActorCpp),
+	fromString("class_h_ActorComponent", templateClassComponentH),
+	fromString("class_cpp_ActorComponent", templateClassComponentCpp),
+	fromString("class_h_SceneComponent", templateClassComponentH),
+	fromString("class_cpp_SceneComponent", templateClassComponentCpp),
+	fromString("class_h_GameInstanceSubsystem", templateClassSubsystemH),
+	fromString("class_cpp_GameInstanceSubsystem", templateClassSubsystemCpp),
+	fromString("class_h_WorldSubsystem", templateClassSubsystemH),
+	fromString("class_cpp_WorldSubsystem", templateClassSubsystemCpp),
+	fromString("class_h_LocalPlayerSubsystem", templateClassSubsystemH),
+	fromString("class_cpp_LocalPlayerSubsystem", templateClassSubsystemCpp),
+	fromString("class_h_DeveloperSettings", templateClassDeveloperSettingsH),
+}
+
+const templateExt = ".tmpl"
+
 func init() {
 	var err error
-	globalTpl, err = loadTemplates(
-		fromString("copyright", templateCopyright),
-		fromString("header", templateHeader),
-		fromString("build_file", templateBuildCs),
-	)
+	globalTpl, err = loadTemplates(builtinTemplates...)
 	if err != nil {
 		panic(fmt.Errorf("failed to load templates: %v", err))
 	}
 }
 
+// ResetTemplates drops the loaded user templates, leaving the built-in ones
+func ResetTemplates() error {
+	tpl, err := loadTemplates(builtinTemplates...)
+	if err != nil {
+		return err
+	}
+	globalTpl = tpl
+	return nil
+}
+
+// LoadTemplateDir loads text/template files with ".tmpl" extension from the directory, other files are ignored.
+// The name of the template is the file name without the extension,
+// templates with the name of a built-in template override it
+func LoadTemplateDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	fs := append([]templateString(nil), builtinTemplates...)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != templateExt {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		fs = append(fs, fromString(strings.TrimSuffix(entry.Name(), templateExt), string(b)))
+	}
+	tpl, err := loadTemplates(fs...)
+	if err != nil {
+		return errs.New(errs.Config, "failed to load templates from %s: %w", dir, err)
+	}
+	globalTpl = tpl
+	return nil
+}
+
+// HasTemplate checks, if the template with the name is loaded. The name may contain ".tmpl" extension
+func HasTemplate(name string) bool {
+	return lookupTemplate(name) != nil
+}
+
+// LoadTemplateFile loads the template file, that is not in the templates directory.
+// The template is available by the file name
+func LoadTemplateFile(p string) (string, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return "", err
+	}
+	name := strings.TrimSuffix(filepath.Base(p), templateExt)
+	_, err = globalTpl.New(name).Parse(string(b))
+	if err != nil {
+		return "", errs.New(errs.Config, "failed to load template %s: %w", p, err)
+	}
+	return name, nil
+}
+
+func lookupTemplate(name string) *template.Template {
+	if tpl := globalTpl.Lookup(name); tpl != nil {
+		return tpl
+	}
+	return globalTpl.Lookup(strings.TrimSuffix(name, templateExt))
+}
+
+// DumpTemplates writes the built-in templates to the directory as a starting point for overrides
+func DumpTemplates(dir string, overwrite bool) ([]string, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+	var written []string
+	for _, f := range builtinTemplates {
+		name, str := f()
+		p := filepath.Join(dir, name+templateExt)
+		if _, err = os.Stat(p); err == nil && !overwrite {
+			return written, errs.New(errs.Conflict, "template file %s already exists", p)
+		}
+		report.Touch(p)
+		err = os.WriteFile(p, []byte(str), 0644)
+		if err != nil {
+			return written, err
+		}
+		written = append(written, p)
+	}
+	return written, nil
+}
+
 func moduleTemplate() *template.Template {
 	return globalTpl.Lookup("header")
 }
 func moduleBuildFile() *template.Template {
 	return globalTpl.Lookup("build_file")
 }
+func moduleCppTemplate() *template.Template {
+	return globalTpl.Lookup("module_cpp")
+}
+func targetFile() *template.Template {
+	return globalTpl.Lookup("target_file")
+}
@@ -0,0 +1,25 @@
+package printer
+
+const templateApiHeader = `
+{{- template "copyright" . }}
+#pragma once
+
+#include "CoreMinimal.h"
+{{- if .StatsGroup }}
+#include "Stats/Stats.h"
+{{- end }}
+
+// Mark classes, structs and functions that are used outside of the {{ .ModuleName }} module with the export macro:
+//   UCLASS()
+//   class {{ .ModuleApi }} UMyObject : public UObject
+//
+//   {{ .ModuleApi }} void MyFunction();
+{{- if .LogCategory }}
+
+{{ .ModuleApi }} DECLARE_LOG_CATEGORY_EXTERN(Log{{ .ModuleName }}, {{ or .LogVerbosity "Log" }}, All);
+{{- end }}
+{{- if .StatsGroup }}
+
+DECLARE_STATS_GROUP(TEXT({{ printf "%q" .ModuleName }}), STATGROUP_{{ .ModuleName }}, STATCAT_Advanced);
+{{- end }}
+`
@@ -1,23 +1,71 @@
 package printer
 
-const templateBuildCs = `
-{{ template "copyright" }}
+// templateAddRange prints AddRange statement, args: property, list of entries, indentation
+const templateAddRange = `
+{{- $name := index . 0 }}{{ $list := index . 1 }}{{ $indent := index . 2 }}
+{{- if $list }}
+{{ $indent }}{{ $name }}.AddRange(new string[] {
+{{- range $dp := $list }}
+{{ $indent }}	{{ printf "%q" $dp }},
+{{- end }}
+{{ $indent }}});
+{{- end }}`
 
+const templateBuildCs = `
+{{- template "copyright" . }}
 using UnrealBuildTool;
 
 public class {{ .ModuleName }} : ModuleRules
 {
-	public {{ .ModuleName }} (ReadOnlyTargetRules Target) : base(Target)
+	public {{ .ModuleName }}(ReadOnlyTargetRules Target) : base(Target)
 	{
-		PCHUsage = PCHUsageMode.UseExplicitOrSharedPCHs;
+		PCHUsage = PCHUsageMode.{{ or .PCHUsage "UseExplicitOrSharedPCHs" }};
+{{- if .PrivatePCHHeaderFile }}
+		PrivatePCHHeaderFile = {{ printf "%q" .PrivatePCHHeaderFile }};
+{{- end }}
+{{- if .CppStandard }}
+		CppStandard = CppStandardVersion.{{ .CppStandard }};
+{{- end }}
+{{- if .IWYUSupport }}
+		IWYUSupport = IWYUSupport.{{ .IWYUSupport }};
+{{- end }}
+{{- if .EnforceIWYU }}
+		bEnforceIWYU = {{ .EnforceIWYU }};
+{{- end }}
+{{- if .UseUnity }}
+		bUseUnity = {{ .UseUnity }};
+{{- end }}
+{{- if .OptimizeCode }}
+		OptimizeCode = CodeOptimization.{{ .OptimizeCode }};
+{{- end }}
+{{- if or .PublicIncludePathModules .PrivateIncludePaths .PublicDefinitions }}
+{{ template "add_range" (args "PublicIncludePathModuleNames" .PublicIncludePathModules "\t\t") }}
+{{- template "add_range" (args "PrivateIncludePaths" .PrivateIncludePaths "\t\t") }}
+{{- range $def := .PublicDefinitions }}
+		PublicDefinitions.Add({{ printf "%q" $def }});
+{{- end }}
+{{- end }}
+{{- if .PublicDependencies }}
+{{ template "add_range" (args "PublicDependencyModuleNames" .PublicDependencies "\t\t") }}
+{{- end }}
+{{- if .PrivateDependencies }}
+{{ template "add_range" (args "PrivateDependencyModuleNames" .PrivateDependencies "\t\t") }}
+{{- end }}
+{{- if .DynamicallyLoaded }}
+{{ template "add_range" (args "DynamicallyLoadedModuleNames" .DynamicallyLoaded "\t\t") }}
+{{- end }}
+{{- range $cond := .Conditions }}
 
-        {{ if .PublicDependencies }}PublicDependencyModuleNames.AddRange(new string[] {
-        {{ range $dp := .PublicDependencies }}  {{ printf "%q" $dp }},
-        {{ end }}});{{ end }}
-        
-        {{ if .PrivateDependencies }}PrivateDependencyModuleNames.AddRange(new string[] {
-        {{ range $dp := .PrivateDependencies }} {{ printf "%q" $dp }},
-        {{ end }}});{{ end }}
-    }
+		if ({{ $cond.Condition }})
+		{
+{{- template "add_range" (args "PublicDependencyModuleNames" $cond.PublicDependencies "\t\t\t") }}
+{{- template "add_range" (args "PrivateDependencyModuleNames" $cond.PrivateDependencies "\t\t\t") }}
+{{- template "add_range" (args "DynamicallyLoadedModuleNames" $cond.DynamicallyLoaded "\t\t\t") }}
+{{- range $def := $cond.PublicDefinitions }}
+			PublicDefinitions.Add({{ printf "%q" $def }});
+{{- end }}
+		}
+{{- end }}
+	}
 }
 `
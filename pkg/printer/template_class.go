@@ -0,0 +1,100 @@
+package printer
+
+const templateClassHeader = `
+{{- template "copyright" . }}
+#pragma once
+
+#include "CoreMinimal.h"
+#include "{{ .ParentInclude }}"
+#include "{{ .ClassName }}.generated.h"
+
+UCLASS({{ join ", " .Specifiers }})
+class {{ .ModuleApi }} {{ .FullName }} : public {{ .Parent }}
+{
+	GENERATED_BODY()
+{{ include (printf "class_h_%s" .Preset) . }}};
+`
+
+const templateClassCpp = `
+{{- template "copyright" . }}
+#include "{{ .HeaderInclude }}"
+{{ include (printf "class_cpp_%s" .Preset) . }}`
+
+const templateClassActorH = `
+public:
+	{{ .FullName }}();
+
+protected:
+	virtual void BeginPlay() override;
+
+public:
+	virtual void Tick(float DeltaTime) override;
+`
+
+const templateClassActorCpp = `
+{{ .FullName }}::{{ .FullName }}()
+{
+	PrimaryActorTick.bCanEverTick = true;
+}
+
+void {{ .FullName }}::BeginPlay()
+{
+	Super::BeginPlay();
+}
+
+void {{ .FullName }}::Tick(float DeltaTime)
+{
+	Super::Tick(DeltaTime);
+}
+`
+
+const templateClassComponentH = `
+public:
+	{{ .FullName }}();
+
+protected:
+	virtual void BeginPlay() override;
+
+public:
+	virtual void TickComponent(float DeltaTime, ELevelTick TickType, FActorComponentTickFunction* ThisTickFunction) override;
+`
+
+const templateClassComponentCpp = `
+{{ .FullName }}::{{ .FullName }}()
+{
+	PrimaryComponentTick.bCanEverTick = true;
+}
+
+void {{ .FullName }}::BeginPlay()
+{
+	Super::BeginPlay();
+}
+
+void {{ .FullName }}::TickComponent(float DeltaTime, ELevelTick TickType, FActorComponentTickFunction* ThisTickFunction)
+{
+	Super::TickComponent(DeltaTime, TickType, ThisTickFunction);
+}
+`
+
+const templateClassSubsystemH = `
+public:
+	virtual void Initialize(FSubsystemCollectionBase& Collection) override;
+	virtual void Deinitialize() override;
+`
+
+const templateClassSubsystemCpp = `
+void {{ .FullName }}::Initialize(FSubsystemCollectionBase& Collection)
+{
+	Super::Initialize(Collection);
+}
+
+void {{ .FullName }}::Deinitialize()
+{
+	Super::Deinitialize();
+}
+`
+
+const templateClassDeveloperSettingsH = `
+public:
+	virtual FName GetCategoryName() const override { return TEXT("Game"); }
+`
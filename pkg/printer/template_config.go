@@ -0,0 +1,44 @@
+package printer
+
+const templateConfigYaml = `# module-tool config, generated by "module-tool init"
+
+project:
+  name: {{ .Name }}
+{{- if not .IsPlugin }}
+  engine_association: {{ printf "%q" .EngineAssociation }} # engine version or the GUID of the source build
+{{- end }}
+  copyright:
+{{- if .Copyright }}
+    text: |
+{{- range $ln := .Copyright }}
+      {{ $ln }}
+{{- end }}
+{{- else }}
+    text: ""
+{{- end }}
+    use_unreal: false # this indicates, if the copyright will be read from the engine's project settings
+
+  category: {{ printf "%q" .Category }}
+  description: {{ printf "%q" .Description }}
+{{- if .IsPlugin }}
+
+  # .uplugin descriptor fields
+  version: 1
+  version_name: "1.0"
+  friendly_name: {{ printf "%q" .Name }}
+  can_contain_content: false
+{{- end }}
+
+# type:          {{ join ", " .ModuleTypes }}
+# loading_phase: {{ join ", " .LoadingPhases }}
+# dependencies:  public evaluates to PublicDependencyModuleNames, private to PrivateDependencyModuleNames
+modules:
+{{- range $mdl := .Modules }}
+  - name: {{ $mdl.Name }}
+    type: {{ $mdl.Type }}
+    loading_phase: {{ $mdl.LoadingPhase }}
+
+    dependencies:
+      public:  [{{ join ", " $mdl.Public }}]
+      private: [{{ join ", " $mdl.Private }}]
+{{ end }}`
@@ -0,0 +1,36 @@
+package printer
+
+const templateExternalBuildCs = `
+{{- template "copyright" . }}
+using System.IO;
+using UnrealBuildTool;
+
+public class {{ .ModuleName }} : ModuleRules
+{
+	public {{ .ModuleName }}(ReadOnlyTargetRules Target) : base(Target)
+	{
+		Type = ModuleType.External;
+{{ range $dir := .IncludeDirs }}
+		PublicIncludePaths.Add(Path.Combine(ModuleDirectory, {{ printf "%q" $dir }}));
+{{- end }}
+{{- range $def := .Definitions }}
+		PublicDefinitions.Add({{ printf "%q" $def }});
+{{- end }}
+{{- range $pl := .Platforms }}
+
+		if (Target.Platform == UnrealTargetPlatform.{{ $pl.Name }})
+		{
+{{- range $lib := $pl.Libraries }}
+			PublicAdditionalLibraries.Add(Path.Combine(ModuleDirectory, {{ printf "%q" $lib }}));
+{{- end }}
+{{- range $dll := $pl.DelayLoadDLLs }}
+			PublicDelayLoadDLLs.Add({{ printf "%q" $dll }});
+{{- end }}
+{{- range $dep := $pl.RuntimeDependencies }}
+			RuntimeDependencies.Add({{ printf "%q" (printf "$(TargetOutputDir)/%s" (base $dep)) }}, Path.Combine(ModuleDirectory, {{ printf "%q" $dep }}));
+{{- end }}
+		}
+{{- end }}
+	}
+}
+`
@@ -0,0 +1,30 @@
+package printer
+
+const templateModuleCpp = `
+{{ template "copyright" . }}
+#include "{{ .ModuleName }}.h"
+{{- if .ApiHeader }}
+#include "{{ .ApiHeader }}"
+{{- end }}
+#include "Modules/ModuleManager.h"
+{{- if .LogCategory }}
+
+DEFINE_LOG_CATEGORY(Log{{ .ModuleName }});
+{{- end }}
+
+void F{{ .ModuleName }}Module::StartupModule()
+{
+}
+
+void F{{ .ModuleName }}Module::ShutdownModule()
+{
+}
+
+{{ if .IsPrimaryGameModule -}}
+IMPLEMENT_PRIMARY_GAME_MODULE(F{{ .ModuleName }}Module, {{ .ModuleName }}, {{ printf "%q" .ProjectName }});
+{{- else if .IsGameModule -}}
+IMPLEMENT_GAME_MODULE(F{{ .ModuleName }}Module, {{ .ModuleName }});
+{{- else -}}
+IMPLEMENT_MODULE(F{{ .ModuleName }}Module, {{ .ModuleName }});
+{{- end }}
+`
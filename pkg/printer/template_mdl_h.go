@@ -1,7 +1,7 @@
 package printer
 
 const templateHeader = `
-{{ template "copyright" }}
+{{ template "copyright" . }}
 #pragma once
 
 #include "CoreMinimal.h"
@@ -0,0 +1,20 @@
+package printer
+
+const templateDefaultPluginIni = `; Default settings of the {{ .PluginName }} plugin
+`
+
+const templateFilterPluginIni = `[FilterPlugin]
+; This section lists additional files which will be packaged along with your plugin. Paths should be listed relative to the root plugin directory, and
+; may include "...", "*", and "?" wildcards to match directories, files, and individual characters respectively.
+;
+; Examples:
+;    /README.txt
+;    /Extras/...
+;    /Binaries/ThirdParty/*.dll
+{{- if .HasConfig }}
+/Config/...
+{{- end }}
+{{- range $p := .FilterPaths }}
+{{ $p }}
+{{- end }}
+`
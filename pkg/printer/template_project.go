@@ -0,0 +1,43 @@
+package printer
+
+const templateDefaultEngineIni = `[URL]
+GameName={{ .ProjectName }}
+
+[/Script/HardwareTargeting.HardwareTargetingSettings]
+TargetedHardwareClass=Desktop
+AppliedTargetedHardwareClass=Desktop
+DefaultGraphicsPerformance=Maximum
+AppliedDefaultGraphicsPerformance=Maximum
+`
+
+const templateDefaultGameIni = `[/Script/EngineSettings.GeneralProjectSettings]
+ProjectID={{ .ProjectID }}
+ProjectName={{ .ProjectName }}
+{{- with $notice := join " " (split "\n" .Copyright) }}
+CopyrightNotice={{ $notice }}
+{{- end }}
+`
+
+const templateGitignore = `# Visual Studio / Rider
+.vs/
+.idea/
+*.sln
+*.suo
+*.opensdf
+*.sdf
+*.VC.db
+*.VC.opendb
+
+# Xcode
+*.xcworkspace
+*.xcodeproj
+
+# Unreal generated folders
+Binaries/
+Build/
+DerivedDataCache/
+Intermediate/
+Saved/
+Plugins/**/Binaries/
+Plugins/**/Intermediate/
+`
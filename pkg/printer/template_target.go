@@ -0,0 +1,20 @@
+package printer
+
+const templateTargetCs = `
+{{- template "copyright" . }}
+using UnrealBuildTool;
+using System.Collections.Generic;
+
+public class {{ .TargetName }}Target : TargetRules
+{
+	public {{ .TargetName }}Target(TargetInfo Target) : base(Target)
+	{
+		Type = TargetType.{{ .Type }};
+		{{ if .BuildSettings }}DefaultBuildSettings = BuildSettingsVersion.{{ .BuildSettings }};{{ end }}
+		{{- if .IncludeOrderVersion }}
+		IncludeOrderVersion = EngineIncludeOrderVersion.{{ .IncludeOrderVersion }};{{ end }}
+
+		ExtraModuleNames.AddRange(new string[] { {{ range $ix, $mdl := .ExtraModules }}{{ if $ix }}, {{ end }}{{ printf "%q" $mdl }}{{ end }} });
+	}
+}
+`
@@ -0,0 +1,118 @@
+// Package report collects what the command did: written files, warnings and the error,
+// so the result can be printed as a single JSON document
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Problem is a single issue of the input file, e.g. of the config
+type Problem struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Message string `json:"message"`
+}
+
+type Error struct {
+	Kind     string    `json:"kind"`
+	Code     int       `json:"code"`
+	Message  string    `json:"message"`
+	Problems []Problem `json:"problems,omitempty"`
+}
+
+type Report struct {
+	Command  string   `json:"command"`
+	OK       bool     `json:"ok"`
+	Project  string   `json:"project,omitempty"`
+	Plugin   string   `json:"plugin,omitempty"`
+	Created  []string `json:"created"`
+	Modified []string `json:"modified"`
+	Warnings []string `json:"warnings"`
+	Result   any      `json:"result,omitempty"`
+	Error    *Error   `json:"error,omitempty"`
+}
+
+var current = newReport()
+
+func newReport() *Report {
+	return &Report{
+		Created:  []string{},
+		Modified: []string{},
+		Warnings: []string{},
+	}
+}
+
+// Current returns the report of the running command
+func Current() *Report {
+	return current
+}
+
+// SetCommand sets the name of the command, e.g. "module create"
+func SetCommand(name string) {
+	current.Command = name
+}
+
+// SetLocation sets the paths of the .uproject and .uplugin, the command works on
+func SetLocation(project, plugin string) {
+	current.Project = project
+	current.Plugin = plugin
+}
+
+func absPath(p string) string {
+	if abs, err := filepath.Abs(p); err == nil {
+		return abs
+	}
+	return p
+}
+
+func contains(ls []string, s string) bool {
+	for _, v := range ls {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Touch records the file, that is about to be written: as created, if it doesn't exist yet, otherwise as modified
+func Touch(p string) {
+	p = absPath(p)
+	if contains(current.Created, p) || contains(current.Modified, p) {
+		return
+	}
+	if _, err := os.Stat(p); errors.Is(err, fs.ErrNotExist) {
+		current.Created = append(current.Created, p)
+	} else {
+		current.Modified = append(current.Modified, p)
+	}
+}
+
+func Warn(format string, args ...any) {
+	current.Warnings = append(current.Warnings, fmt.Sprintf(format, args...))
+}
+
+// SetResult sets the output of the command, e.g. the generated document
+func SetResult(v any) {
+	current.Result = v
+}
+
+// Fail records the error of the command
+func Fail(e *Error) {
+	current.OK = false
+	current.Error = e
+}
+
+// Print writes the report as JSON
+func Print(w io.Writer) error {
+	current.OK = current.Error == nil
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(current)
+}
@@ -0,0 +1,220 @@
+package target
+
+import (
+	"errors"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/cs"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/errs"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/printer"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/report"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/ue"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	fileSuffix      = ".Target.cs"
+	extraModuleList = "ExtraModuleNames"
+)
+
+type Settings struct {
+	Copyright           string
+	BuildSettings       string
+	IncludeOrderVersion string
+}
+
+// File is the parsed .Target.cs file
+type File struct {
+	Path string
+	Name string
+	Type ue.TargetType
+
+	source *cs.Source
+}
+
+func FileName(projectName string, t ue.TargetType) string {
+	return projectName + t.Suffix() + fileSuffix
+}
+
+func Read(path string) (*File, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f := &File{
+		Path:   path,
+		Name:   strings.TrimSuffix(filepath.Base(path), fileSuffix),
+		Type:   ue.TargetGame,
+		source: cs.Parse(b),
+	}
+	if v, ok := f.source.Value("Type"); ok {
+		t := ue.StrToTargetType(strings.TrimPrefix(v, "TargetType."))
+		if t < 0 {
+			return nil, errs.New(errs.Validation, "%s: unknown target type %q", path, v)
+		}
+		f.Type = t
+	}
+	return f, nil
+}
+
+// List reads all target files from the project's Source folder
+func List(projectFile *ue.ProjectFileDescriptor) ([]*File, error) {
+	entries, err := os.ReadDir(projectFile.Sources())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var files []*File
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), fileSuffix) {
+			continue
+		}
+		f, err := Read(filepath.Join(projectFile.Sources(), entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+func (f *File) Modules() []string {
+	return f.source.Entries(extraModuleList)
+}
+
+func (f *File) AddModules(names ...string) error {
+	return f.source.Add(extraModuleList, names...)
+}
+
+func (f *File) RemoveModules(names ...string) bool {
+	return f.source.Remove(extraModuleList, names...)
+}
+
+func (f *File) Write() error {
+	report.Touch(f.Path)
+	return os.WriteFile(f.Path, f.source.Bytes(), 0644)
+}
+
+// Accepts checks, if the module of the given type belongs to the target's ExtraModuleNames
+func (f *File) Accepts(mdl *ue.ProjectModuleDescriptor) bool {
+	if mdl.Type == ue.ModuleRuntime {
+		return true
+	}
+	return f.Type == ue.TargetEditor
+}
+
+// Create writes new target file of the project
+func Create(projectFile *ue.ProjectFileDescriptor, t ue.TargetType, settings Settings, modules []string) (*File, error) {
+	if projectFile.IsPlugin {
+		return nil, errs.New(errs.Validation, "plugins can't have targets")
+	}
+	if t.String() == "" {
+		return nil, errs.New(errs.Validation, "invalid target type")
+	}
+	p := filepath.Join(projectFile.Sources(), FileName(projectFile.ProjectName, t))
+	if _, err := os.Stat(p); err == nil {
+		return nil, errs.New(errs.Conflict, "target file %s already exists", p)
+	}
+	err := os.MkdirAll(projectFile.Sources(), 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	report.Touch(p)
+	f, err := os.Create(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	err = printer.PrintTargetCs(printer.TargetFileCtx{
+		Copyright:           settings.Copyright,
+		TargetName:          projectFile.ProjectName + t.Suffix(),
+		Type:                t.String(),
+		BuildSettings:       settings.BuildSettings,
+		IncludeOrderVersion: settings.IncludeOrderVersion,
+		ExtraModules:        modules,
+	}, f)
+	if err != nil {
+		return nil, err
+	}
+	_ = f.Close()
+	return Read(p)
+}
+
+// EnsureTargets creates target files of the given types, if the project has none
+func EnsureTargets(projectFile *ue.ProjectFileDescriptor, types []ue.TargetType, settings Settings) ([]*File, error) {
+	files, err := List(projectFile)
+	if err != nil || len(files) > 0 {
+		return files, err
+	}
+	if len(types) == 0 {
+		types = []ue.TargetType{ue.TargetGame, ue.TargetEditor}
+	}
+	for _, t := range types {
+		var modules []string
+		for _, mdl := range projectFile.Modules {
+			if mdl.Type == ue.ModuleRuntime || t == ue.TargetEditor {
+				modules = append(modules, mdl.Name)
+			}
+		}
+		f, err := Create(projectFile, t, settings, modules)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// AddModule registers the module in ExtraModuleNames of the project targets.
+// Returns the paths of the modified files
+func AddModule(projectFile *ue.ProjectFileDescriptor, mdl *ue.ProjectModuleDescriptor) ([]string, error) {
+	files, err := List(projectFile)
+	if err != nil {
+		return nil, err
+	}
+	var modified []string
+	for _, f := range files {
+		if !f.Accepts(mdl) {
+			continue
+		}
+		before := f.source.String()
+		err = f.AddModules(mdl.Name)
+		if err != nil {
+			return modified, errs.New(errs.Validation, "%s: %w", f.Path, err)
+		}
+		if before == f.source.String() {
+			continue
+		}
+		err = f.Write()
+		if err != nil {
+			return modified, err
+		}
+		modified = append(modified, f.Path)
+	}
+	return modified, nil
+}
+
+// RemoveModule removes the module from ExtraModuleNames of all project targets.
+// Returns the paths of the modified files
+func RemoveModule(projectFile *ue.ProjectFileDescriptor, name string) ([]string, error) {
+	files, err := List(projectFile)
+	if err != nil {
+		return nil, err
+	}
+	var modified []string
+	for _, f := range files {
+		if !f.RemoveModules(name) {
+			continue
+		}
+		err = f.Write()
+		if err != nil {
+			return modified, err
+		}
+		modified = append(modified, f.Path)
+	}
+	return modified, nil
+}
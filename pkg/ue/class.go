@@ -0,0 +1,115 @@
+package ue
+
+import (
+	"path"
+	"strings"
+)
+
+// ClassPreset describes the base class of the generated UObject-derived class
+type ClassPreset struct {
+	Name       string
+	Parent     string
+	Include    string
+	Specifiers []string
+	// Modules are required by the module with the class
+	Modules []string
+}
+
+var ClassPresets = []ClassPreset{
+	{Name: "Actor", Parent: "AActor", Include: "GameFramework/Actor.h"},
+	{Name: "ActorComponent", Parent: "UActorComponent", Include: "Components/ActorComponent.h",
+		Specifiers: []string{"ClassGroup=(Custom)", "meta=(BlueprintSpawnableComponent)"}},
+	{Name: "SceneComponent", Parent: "USceneComponent", Include: "Components/SceneComponent.h",
+		Specifiers: []string{"ClassGroup=(Custom)", "meta=(BlueprintSpawnableComponent)"}},
+	{Name: "Object", Parent: "UObject", Include: "UObject/Object.h",
+		Specifiers: []string{"BlueprintType"}},
+	{Name: "GameInstanceSubsystem", Parent: "UGameInstanceSubsystem", Include: "Subsystems/GameInstanceSubsystem.h"},
+	{Name: "WorldSubsystem", Parent: "UWorldSubsystem", Include: "Subsystems/WorldSubsystem.h"},
+	{Name: "LocalPlayerSubsystem", Parent: "ULocalPlayerSubsystem", Include: "Subsystems/LocalPlayerSubsystem.h"},
+	{Name: "DeveloperSettings", Parent: "UDeveloperSettings", Include: "Engine/DeveloperSettings.h",
+		Specifiers: []string{"Config=Game", "DefaultConfig"}, Modules: []string{"DeveloperSettings"}},
+	{Name: "BlueprintFunctionLibrary", Parent: "UBlueprintFunctionLibrary", Include: "Kismet/BlueprintFunctionLibrary.h"},
+	{Name: "UserWidget", Parent: "UUserWidget", Include: "Blueprint/UserWidget.h", Modules: []string{"UMG"}},
+}
+
+// classModules are the modules of the engine classes, that are not in the default dependencies
+var classModules = map[string]string{
+	"UDeveloperSettings":                 "DeveloperSettings",
+	"UUserWidget":                        "UMG",
+	"UCommonUserWidget":                  "CommonUI",
+	"UCommonActivatableWidget":           "CommonUI",
+	"UGameplayTagsManager":               "GameplayTags",
+	"UGameplayTagsSettings":              "GameplayTags",
+	"UInputAction":                       "EnhancedInput",
+	"UInputMappingContext":               "EnhancedInput",
+	"UInputModifier":                     "EnhancedInput",
+	"UInputTrigger":                      "EnhancedInput",
+	"UEnhancedInputComponent":            "EnhancedInput",
+	"UEnhancedInputLocalPlayerSubsystem": "EnhancedInput",
+	"UEnhancedPlayerInput":               "EnhancedInput",
+	"UAbilitySystemComponent":            "GameplayAbilities",
+	"UGameplayAbility":                   "GameplayAbilities",
+	"UAttributeSet":                      "GameplayAbilities",
+}
+
+// ClassModule returns the engine module of the class, if it's not one of the default dependencies
+func ClassModule(className string) (string, bool) {
+	mdl, ok := classModules[className]
+	return mdl, ok
+}
+
+func FindClassPreset(name string) *ClassPreset {
+	for i := range ClassPresets {
+		if strings.EqualFold(ClassPresets[i].Name, name) {
+			return &ClassPresets[i]
+		}
+	}
+	return nil
+}
+
+func FindClassPresetByParent(parent string) *ClassPreset {
+	for i := range ClassPresets {
+		if ClassPresets[i].Parent == parent {
+			return &ClassPresets[i]
+		}
+	}
+	return nil
+}
+
+// ClassDescriptor is the UObject-derived class generated in the module
+type ClassDescriptor struct {
+	Module     string
+	Name       string
+	Parent     string
+	Include    string
+	Dir        string
+	Preset     string
+	Specifiers []string
+	Modules    []string
+}
+
+// Prefix is the prefix of the class name, inherited from the parent class
+func (c *ClassDescriptor) Prefix() string {
+	if strings.HasPrefix(c.Parent, "A") {
+		return "A"
+	}
+	return "U"
+}
+
+func (c *ClassDescriptor) FullName() string {
+	return c.Prefix() + c.Name
+}
+
+// HeaderInclude is the path of the header relative to the module's Public folder
+func (c *ClassDescriptor) HeaderInclude() string {
+	return path.Join(c.Dir, c.Name+".h")
+}
+
+func (c *ClassDescriptor) SourceFile() string {
+	return path.Join(c.Dir, c.Name+".cpp")
+}
+
+// ApiMacro returns the export macro of the module, e.g. MYMODULE_API
+func ApiMacro(moduleName string) string {
+	return strings.ToUpper(moduleName) + "_API"
+}
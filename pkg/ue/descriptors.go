@@ -1,25 +1,132 @@
 package ue
 
-import "path/filepath"
+import (
+	"encoding/json"
+	"path/filepath"
+)
 
 type ProjectModuleDescriptor struct {
 	Name                   string       `json:"Name"`
 	LoadingPhase           LoadingPhase `json:"LoadingPhase"`
 	Type                   ModuleType   `json:"Type"`
 	AdditionalDependencies []string     `json:"AdditionalDependencies,omitempty"`
+
+	raw *rawObject
+}
+
+func (m *ProjectModuleDescriptor) UnmarshalJSON(b []byte) error {
+	type module ProjectModuleDescriptor
+	raw, err := parseRawObject(b)
+	if err != nil {
+		return err
+	}
+	m.raw = raw
+	return json.Unmarshal(b, (*module)(m))
+}
+
+// MarshalJSON writes the module over the entry it was read from. The type and the loading phase,
+// that the tool doesn't know, are written back as they were read
+func (m *ProjectModuleDescriptor) MarshalJSON() ([]byte, error) {
+	type module ProjectModuleDescriptor
+	var keep []string
+	if m.Type < 0 {
+		keep = append(keep, "Type")
+	}
+	if m.LoadingPhase < 0 {
+		keep = append(keep, "LoadingPhase")
+	}
+	return marshalOver(m.raw, (*module)(m), keep...)
+}
+
+// TypeName returns the type of the module as it is in the descriptor, including the types, the tool doesn't know
+func (m *ProjectModuleDescriptor) TypeName() string {
+	if m.Type < 0 {
+		return m.raw.str("Type")
+	}
+	return m.Type.String()
+}
+
+// LoadingPhaseName returns the loading phase of the module as it is in the descriptor, including the phases, the tool doesn't know
+func (m *ProjectModuleDescriptor) LoadingPhaseName() string {
+	if m.LoadingPhase < 0 {
+		return m.raw.str("LoadingPhase")
+	}
+	return m.LoadingPhase.String()
 }
 
 type PluginDescriptor struct {
 	Name                     string   `json:"Name"`
 	Enabled                  bool     `json:"Enabled"`
+	Optional                 bool     `json:"Optional,omitempty"`
 	SupportedTargetPlatforms []string `json:"SupportedTargetPlatforms,omitempty"`
+
+	raw *rawObject
+}
+
+func (p *PluginDescriptor) UnmarshalJSON(b []byte) error {
+	type plugin PluginDescriptor
+	raw, err := parseRawObject(b)
+	if err != nil {
+		return err
+	}
+	p.raw = raw
+	return json.Unmarshal(b, (*plugin)(p))
+}
+
+func (p *PluginDescriptor) MarshalJSON() ([]byte, error) {
+	type plugin PluginDescriptor
+	return marshalOver(p.raw, (*plugin)(p))
+}
+
+// PluginFileDescriptor is the contents of the .uplugin file.
+// The keys, that are not modelled, e.g. PreBuildSteps or LocalizationTargets, are kept when the file is written back
+type PluginFileDescriptor struct {
+	FileVersion           int                        `json:"FileVersion"`
+	Version               int                        `json:"Version"`
+	VersionName           string                     `json:"VersionName"`
+	FriendlyName          string                     `json:"FriendlyName"`
+	Description           string                     `json:"Description"`
+	Category              string                     `json:"Category"`
+	CreatedBy             string                     `json:"CreatedBy"`
+	CreatedByURL          string                     `json:"CreatedByURL"`
+	DocsURL               string                     `json:"DocsURL"`
+	MarketplaceURL        string                     `json:"MarketplaceURL"`
+	SupportURL            string                     `json:"SupportURL"`
+	EngineVersion         string                     `json:"EngineVersion,omitempty"`
+	CanContainContent     bool                       `json:"CanContainContent"`
+	IsBetaVersion         bool                       `json:"IsBetaVersion"`
+	IsExperimentalVersion bool                       `json:"IsExperimentalVersion"`
+	Installed             bool                       `json:"Installed"`
+	EnabledByDefault      *bool                      `json:"EnabledByDefault,omitempty"`
+	Modules               []*ProjectModuleDescriptor `json:"Modules,omitempty"`
+	Plugins               []*PluginDescriptor        `json:"Plugins,omitempty"`
+
+	raw *rawObject
+}
+
+func (p *PluginFileDescriptor) UnmarshalJSON(b []byte) error {
+	type plugin PluginFileDescriptor
+	raw, err := parseRawObject(b)
+	if err != nil {
+		return err
+	}
+	p.raw = raw
+	return json.Unmarshal(b, (*plugin)(p))
 }
 
+func (p *PluginFileDescriptor) MarshalJSON() ([]byte, error) {
+	type plugin PluginFileDescriptor
+	return marshalOver(p.raw, (*plugin)(p))
+}
+
+// ProjectFileDescriptor is the contents of the .uproject file, or the part of the .uplugin shared with it.
+// The keys, that are not modelled, are kept when the file is written back
 type ProjectFileDescriptor struct {
-	ProjectPath     string `json:"-"`
-	ProjectFileName string `json:"-"`
-	ProjectName     string `json:"-"`
-	IsPlugin        bool   `json:"-"`
+	ProjectPath     string                `json:"-"`
+	ProjectFileName string                `json:"-"`
+	ProjectName     string                `json:"-"`
+	IsPlugin        bool                  `json:"-"`
+	Plugin          *PluginFileDescriptor `json:"-"`
 
 	FileVersion       int                        `json:"FileVersion"`
 	EngineAssociation string                     `json:"EngineAssociation"`
@@ -28,12 +135,82 @@ type ProjectFileDescriptor struct {
 	Modules           []*ProjectModuleDescriptor `json:"Modules,omitempty"`
 	Plugins           []*PluginDescriptor        `json:"Plugins,omitempty"`
 	TargetPlatforms   []string                   `json:"TargetPlatforms,omitempty"`
+	// AdditionalRootDirectories and AdditionalPluginDirectories are relative to the project, or absolute
+	AdditionalRootDirectories   []string `json:"AdditionalRootDirectories,omitempty"`
+	AdditionalPluginDirectories []string `json:"AdditionalPluginDirectories,omitempty"`
+
+	raw *rawObject
+}
+
+func (p *ProjectFileDescriptor) UnmarshalJSON(b []byte) error {
+	type project ProjectFileDescriptor
+	raw, err := parseRawObject(b)
+	if err != nil {
+		return err
+	}
+	p.raw = raw
+	return json.Unmarshal(b, (*project)(p))
+}
+
+func (p *ProjectFileDescriptor) MarshalJSON() ([]byte, error) {
+	type project ProjectFileDescriptor
+	return marshalOver(p.raw, (*project)(p))
 }
 
 func (p *ProjectFileDescriptor) Path() string {
 	return filepath.Join(p.ProjectPath, p.ProjectFileName)
 }
 
+// ResolvePath returns the absolute path of the path from the descriptor
+func (p *ProjectFileDescriptor) ResolvePath(path string) string {
+	path = filepath.FromSlash(path)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(p.ProjectPath, path)
+	}
+	return filepath.Clean(path)
+}
+
+// PluginDirectories returns the directories to search the plugins in: the Plugins folder,
+// the additional plugin directories, and the Plugins folders of the additional root directories
+func (p *ProjectFileDescriptor) PluginDirectories() []string {
+	dirs := []string{filepath.Join(p.ProjectPath, "Plugins")}
+	for _, dir := range p.AdditionalPluginDirectories {
+		dirs = append(dirs, p.ResolvePath(dir))
+	}
+	for _, dir := range p.AdditionalRootDirectories {
+		dirs = append(dirs, filepath.Join(p.ResolvePath(dir), "Plugins"))
+	}
+	return dirs
+}
+
+// AddPluginDirectory adds the directory to AdditionalPluginDirectories, the path is stored relative to the project.
+// Returns false if the directory is already there
+func (p *ProjectFileDescriptor) AddPluginDirectory(dir string) bool {
+	abs := p.ResolvePath(dir)
+	for _, d := range p.AdditionalPluginDirectories {
+		if p.ResolvePath(d) == abs {
+			return false
+		}
+	}
+	if rel, err := filepath.Rel(p.ProjectPath, abs); err == nil {
+		abs = rel
+	}
+	p.AdditionalPluginDirectories = append(p.AdditionalPluginDirectories, filepath.ToSlash(abs))
+	return true
+}
+
+// RemovePluginDirectory removes the directory from AdditionalPluginDirectories, returns false if it is not there
+func (p *ProjectFileDescriptor) RemovePluginDirectory(dir string) bool {
+	abs := p.ResolvePath(dir)
+	for i, d := range p.AdditionalPluginDirectories {
+		if p.ResolvePath(d) == abs {
+			p.AdditionalPluginDirectories = append(p.AdditionalPluginDirectories[:i], p.AdditionalPluginDirectories[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
 func (p *ProjectFileDescriptor) Sources() string {
 	return filepath.Join(p.ProjectPath, "Source")
 }
@@ -42,6 +219,11 @@ func (p *ProjectFileDescriptor) ModuleSources(mdl string) string {
 	return filepath.Join(p.ProjectPath, "Source", mdl)
 }
 
+// ThirdPartySources is the folder of the external module wrapping a third-party library
+func (p *ProjectFileDescriptor) ThirdPartySources(lib string) string {
+	return filepath.Join(p.ProjectPath, "Source", "ThirdParty", lib)
+}
+
 func (p *ProjectFileDescriptor) ModulePublic(mdl string) string {
 	return filepath.Join(p.ProjectPath, "Source", mdl, "Public")
 }
@@ -50,8 +232,30 @@ func (p *ProjectFileDescriptor) ModulePrivate(mdl string) string {
 	return filepath.Join(p.ProjectPath, "Source", mdl, "Private")
 }
 
+// Descriptor returns the value that is serialized into the descriptor file.
+// For plugins, modules and plugin references are synced into the .uplugin descriptor
+func (p *ProjectFileDescriptor) Descriptor() any {
+	if !p.IsPlugin {
+		return p
+	}
+	if p.Plugin == nil {
+		p.Plugin = &PluginFileDescriptor{
+			FileVersion:  p.FileVersion,
+			FriendlyName: p.ProjectName,
+		}
+	}
+	p.Plugin.FileVersion = p.FileVersion
+	p.Plugin.Modules = p.Modules
+	p.Plugin.Plugins = p.Plugins
+	return p.Plugin
+}
+
 func (p *ProjectFileDescriptor) Touch() {
 	// format for ue4.27
-	p.FileVersion = 3
-	p.EngineAssociation = "4.27"
+	if p.FileVersion == 0 {
+		p.FileVersion = 3
+	}
+	if p.EngineAssociation == "" && !p.IsPlugin {
+		p.EngineAssociation = "4.27"
+	}
 }
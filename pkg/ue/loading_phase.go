@@ -2,14 +2,16 @@ package ue
 
 import (
 	"encoding/json"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/errs"
 	"gopkg.in/yaml.v3"
 )
 
 type LoadingPhase int
 
+// LoadingPhaseDefault is the zero value, so the phase omitted in the config is "Default"
 const (
-	LoadingPhasePreDefault LoadingPhase = iota
-	LoadingPhaseDefault
+	LoadingPhaseDefault LoadingPhase = iota
+	LoadingPhasePreDefault
 	LoadingPhasePostEngineInit
 )
 
@@ -70,3 +72,16 @@ func (lp *LoadingPhase) UnmarshalYAML(value *yaml.Node) error {
 func (lp *LoadingPhase) MarshalYAML() (interface{}, error) {
 	return lp.String(), nil
 }
+
+// LoadingPhases returns the names of the valid values
+func LoadingPhases() []string {
+	return []string{lpPreDefault, lpDefault, lpPostEngineInit}
+}
+
+func ParseLoadingPhase(str string) (LoadingPhase, error) {
+	v := stringToLp(str)
+	if v < 0 {
+		return v, errs.New(errs.Validation, "unknown loading phase %q, want one of %v", str, LoadingPhases())
+	}
+	return v, nil
+}
@@ -2,6 +2,7 @@ package ue
 
 import (
 	"encoding/json"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/errs"
 	"gopkg.in/yaml.v3"
 )
 
@@ -70,3 +71,16 @@ func (m *ModuleType) UnmarshalYAML(value *yaml.Node) error {
 func (m *ModuleType) MarshalYAML() (interface{}, error) {
 	return m.String(), nil
 }
+
+// ModuleTypes returns the names of the valid values
+func ModuleTypes() []string {
+	return []string{mRuntime, mEditor, mUncooked}
+}
+
+func ParseModuleType(str string) (ModuleType, error) {
+	v := strToMt(str)
+	if v < 0 {
+		return v, errs.New(errs.Validation, "unknown module type %q, want one of %v", str, ModuleTypes())
+	}
+	return v, nil
+}
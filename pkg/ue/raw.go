@@ -0,0 +1,168 @@
+package ue
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// rawObject is the JSON object the descriptor was read from. The keys keep their order,
+// so the descriptor is written back without the reordering and the loss of the keys, the tool doesn't model
+type rawObject struct {
+	keys   []string
+	values map[string]json.RawMessage
+}
+
+func parseRawObject(b []byte) (*rawObject, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if tok != json.Delim('{') {
+		return nil, errors.New("descriptor must be a JSON object")
+	}
+	obj := &rawObject{values: make(map[string]json.RawMessage)}
+	for dec.More() {
+		tok, err = dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key := tok.(string)
+		var val json.RawMessage
+		err = dec.Decode(&val)
+		if err != nil {
+			return nil, err
+		}
+		obj.set(key, val)
+	}
+	return obj, nil
+}
+
+func (o *rawObject) set(key string, val json.RawMessage) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = val
+}
+
+func (o *rawObject) remove(key string) {
+	if _, ok := o.values[key]; !ok {
+		return
+	}
+	delete(o.values, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i:i], o.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// str returns the string value of the key, or empty string
+func (o *rawObject) str(key string) string {
+	if o == nil {
+		return ""
+	}
+	var s string
+	_ = json.Unmarshal(o.values[key], &s)
+	return s
+}
+
+func (o *rawObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(o.values[key])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// jsonKeys returns the keys of the struct fields
+func jsonKeys(t reflect.Type) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		keys = append(keys, name)
+	}
+	return keys
+}
+
+func contains(ls []string, s string) bool {
+	for _, v := range ls {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func isZeroJSON(val json.RawMessage) bool {
+	switch string(bytes.TrimSpace(val)) {
+	case `""`, "0", "false", "null", "[]", "{}":
+		return true
+	}
+	return false
+}
+
+// marshalOver marshals the known fields of the descriptor v (pointer to the struct) over the object it was read from:
+// the unknown keys keep their values and places, the known keys are replaced, or removed, if they are cleared now.
+// The known keys, that were not in the file, are added only if they are set. The keys in keep
+// have the values of the file, e.g. the enum values, the tool doesn't know
+func marshalOver(raw *rawObject, v any, keep ...string) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	err := enc.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	b := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+	if raw == nil {
+		return b, nil
+	}
+	known, err := parseRawObject(b)
+	if err != nil {
+		return nil, err
+	}
+	res := &rawObject{keys: append([]string(nil), raw.keys...), values: make(map[string]json.RawMessage)}
+	for key, val := range raw.values {
+		res.values[key] = val
+	}
+	for _, key := range jsonKeys(reflect.TypeOf(v).Elem()) {
+		val, ok := known.values[key]
+		old, existed := raw.values[key]
+		switch {
+		case existed && contains(keep, key):
+		case !ok:
+			// omitted empty value, the empty value of the file is kept as is
+			if existed && !isZeroJSON(old) {
+				res.remove(key)
+			}
+		case existed || !isZeroJSON(val):
+			res.set(key, val)
+		}
+	}
+	return res.MarshalJSON()
+}
@@ -0,0 +1,100 @@
+package ue
+
+import (
+	"encoding/json"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/errs"
+	"gopkg.in/yaml.v3"
+)
+
+type TargetType int
+
+const (
+	TargetGame TargetType = iota
+	TargetEditor
+	TargetClient
+	TargetServer
+)
+
+const (
+	tGame   = "Game"
+	tEditor = "Editor"
+	tClient = "Client"
+	tServer = "Server"
+)
+
+func (t TargetType) String() string {
+	switch t {
+	case TargetGame:
+		return tGame
+	case TargetEditor:
+		return tEditor
+	case TargetClient:
+		return tClient
+	case TargetServer:
+		return tServer
+	}
+	return ""
+}
+
+// Suffix is appended to the project name to get the target name
+func (t TargetType) Suffix() string {
+	if t == TargetGame {
+		return ""
+	}
+	return t.String()
+}
+
+func StrToTargetType(str string) TargetType {
+	switch str {
+	case tGame:
+		return TargetGame
+	case tEditor:
+		return TargetEditor
+	case tClient:
+		return TargetClient
+	case tServer:
+		return TargetServer
+	}
+	return -1
+}
+
+func (t *TargetType) UnmarshalJSON(bytes []byte) error {
+	var str string
+	err := json.Unmarshal(bytes, &str)
+	if err != nil {
+		return err
+	}
+	*t = StrToTargetType(str)
+	return nil
+}
+
+func (t *TargetType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+func (t *TargetType) UnmarshalYAML(value *yaml.Node) error {
+	var str string
+	err := value.Decode(&str)
+	if err != nil {
+		return err
+	}
+	*t = StrToTargetType(str)
+	return nil
+}
+
+func (t *TargetType) MarshalYAML() (interface{}, error) {
+	return t.String(), nil
+}
+
+// TargetTypes returns the names of the valid values
+func TargetTypes() []string {
+	return []string{tGame, tEditor, tClient, tServer}
+}
+
+func ParseTargetType(str string) (TargetType, error) {
+	v := StrToTargetType(str)
+	if v < 0 {
+		return v, errs.New(errs.Validation, "unknown target type %q, want one of %v", str, TargetTypes())
+	}
+	return v, nil
+}
@@ -0,0 +1,241 @@
+package wizard
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/errs"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/printer"
+	"github.com/sajoniks/ue-tools/module-tool/pkg/ue"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var identRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Wizard asks the questions line by line. When Yes is set, or the input is over,
+// the default answers are used, so the wizard can be scripted
+type Wizard struct {
+	Yes bool
+
+	in  *bufio.Reader
+	out io.Writer
+	eof bool
+}
+
+func New(in io.Reader, out io.Writer, yes bool) *Wizard {
+	return &Wizard{
+		Yes: yes,
+		in:  bufio.NewReader(in),
+		out: out,
+	}
+}
+
+// Ask prints the question and reads the answer until it passes the validation
+func (w *Wizard) Ask(question, def string, validate func(string) error) (string, error) {
+	for {
+		if def != "" {
+			fmt.Fprintf(w.out, "%s [%s]: ", question, def)
+		} else {
+			fmt.Fprintf(w.out, "%s: ", question)
+		}
+
+		answer := def
+		if w.Yes || w.eof {
+			fmt.Fprintln(w.out, def)
+		} else {
+			line, err := w.in.ReadString('\n')
+			if err != nil && !errors.Is(err, io.EOF) {
+				return "", err
+			}
+			if errors.Is(err, io.EOF) {
+				w.eof = true
+				if line == "" {
+					fmt.Fprintln(w.out)
+				}
+			}
+			if line = strings.TrimSpace(line); line != "" {
+				answer = line
+			}
+		}
+
+		if validate == nil {
+			return answer, nil
+		}
+		err := validate(answer)
+		if err == nil {
+			return answer, nil
+		}
+		if w.Yes || w.eof {
+			return "", errs.New(errs.Validation, "%s: %w", question, err)
+		}
+		fmt.Fprintf(w.out, "  invalid answer: %v\n", err)
+	}
+}
+
+func (w *Wizard) Confirm(question string, def bool) (bool, error) {
+	d := "n"
+	if def {
+		d = "y"
+	}
+	answer, err := w.Ask(question+" (y/n)", d, func(s string) error {
+		switch strings.ToLower(s) {
+		case "y", "yes", "n", "no":
+			return nil
+		}
+		return fmt.Errorf("want y or n")
+	})
+	if err != nil {
+		return false, err
+	}
+	return strings.HasPrefix(strings.ToLower(answer), "y"), nil
+}
+
+func validateIdent(s string) error {
+	if !identRe.MatchString(s) {
+		return fmt.Errorf("%q is not a valid identifier", s)
+	}
+	return nil
+}
+
+func splitList(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+}
+
+func validateList(s string) error {
+	for _, item := range splitList(s) {
+		if err := validateIdent(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Defaults are the default answers of the wizard
+type Defaults struct {
+	Name              string
+	IsPlugin          bool
+	EngineAssociation string
+}
+
+// Run asks the questions and writes the commented config to the writer
+func (w *Wizard) Run(defaults Defaults, dst io.Writer) error {
+	ctx := printer.ConfigCtx{
+		ModuleTypes:   ue.ModuleTypes(),
+		LoadingPhases: ue.LoadingPhases(),
+	}
+
+	var err error
+	ctx.IsPlugin, err = w.Confirm("Is it a plugin?", defaults.IsPlugin)
+	if err != nil {
+		return err
+	}
+	ctx.Name, err = w.Ask("Project name", defaults.Name, validateIdent)
+	if err != nil {
+		return err
+	}
+	if !ctx.IsPlugin {
+		ctx.EngineAssociation, err = w.Ask("Engine association", defaults.EngineAssociation, nil)
+		if err != nil {
+			return err
+		}
+	}
+	copyright, err := w.Ask("Copyright notice", "", nil)
+	if err != nil {
+		return err
+	}
+	if copyright != "" {
+		ctx.Copyright = strings.Split(copyright, `\n`)
+	}
+	if ctx.IsPlugin {
+		ctx.Category, err = w.Ask("Category", "Other", nil)
+		if err != nil {
+			return err
+		}
+		ctx.Description, err = w.Ask("Description", "", nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	names := make(map[string]bool)
+	for {
+		mdl, err := w.askModule(ctx.Name, len(ctx.Modules), names)
+		if err != nil {
+			return err
+		}
+		ctx.Modules = append(ctx.Modules, mdl)
+		names[mdl.Name] = true
+
+		more, err := w.Confirm("Add another module?", false)
+		if err != nil {
+			return err
+		}
+		if !more {
+			break
+		}
+	}
+
+	return printer.PrintConfig(ctx, dst)
+}
+
+func (w *Wizard) askModule(projectName string, index int, names map[string]bool) (printer.ConfigModuleCtx, error) {
+	var mdl printer.ConfigModuleCtx
+	def := projectName
+	if index > 0 {
+		def = fmt.Sprintf("%sEditor", projectName)
+	}
+	if names[def] {
+		def = ""
+	}
+
+	var err error
+	fmt.Fprintf(w.out, "Module #%d\n", index+1)
+	mdl.Name, err = w.Ask("  Name", def, func(s string) error {
+		if names[s] {
+			return fmt.Errorf("module %q is already defined", s)
+		}
+		return validateIdent(s)
+	})
+	if err != nil {
+		return mdl, err
+	}
+
+	defType := ue.ModuleRuntime.String()
+	if strings.HasSuffix(mdl.Name, "Editor") {
+		defType = ue.ModuleEditor.String()
+	}
+	mdl.Type, err = w.Ask(fmt.Sprintf("  Type (%s)", strings.Join(ue.ModuleTypes(), ", ")), defType, func(s string) error {
+		_, err := ue.ParseModuleType(s)
+		return err
+	})
+	if err != nil {
+		return mdl, err
+	}
+	mdl.LoadingPhase, err = w.Ask(fmt.Sprintf("  Loading phase (%s)", strings.Join(ue.LoadingPhases(), ", ")), ue.LoadingPhaseDefault.String(), func(s string) error {
+		_, err := ue.ParseLoadingPhase(s)
+		return err
+	})
+	if err != nil {
+		return mdl, err
+	}
+
+	public, err := w.Ask("  Public dependencies", "Core, CoreUObject, Engine", validateList)
+	if err != nil {
+		return mdl, err
+	}
+	mdl.Public = splitList(public)
+	defPrivate := ""
+	if mdl.Type != ue.ModuleRuntime.String() {
+		defPrivate = "UnrealEd"
+	}
+	private, err := w.Ask("  Private dependencies", defPrivate, validateList)
+	if err != nil {
+		return mdl, err
+	}
+	mdl.Private = splitList(private)
+	return mdl, nil
+}